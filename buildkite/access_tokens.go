@@ -0,0 +1,57 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+// AccessTokensService handles communication with the access token related
+// methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/access-token
+type AccessTokensService struct {
+	client *Client
+}
+
+// AccessToken represents the access token used to authenticate with buildkite.
+type AccessToken struct {
+	UUID   *string  `json:"uuid,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Get the access token used to authenticate the client.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/access-token#get-the-current-token
+func (ats *AccessTokensService) Get() (*AccessToken, *Response, error) {
+
+	u := "v2/access-token"
+
+	req, err := ats.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := new(AccessToken)
+	resp, err := ats.client.Do(req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return token, resp, err
+}
+
+// Revoke the access token used to authenticate the client. Any further
+// requests made with the client will fail to authenticate.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/access-token#revoke-the-current-token
+func (ats *AccessTokensService) Revoke() (*Response, error) {
+
+	u := "v2/access-token"
+
+	req, err := ats.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ats.client.Do(req, nil)
+}
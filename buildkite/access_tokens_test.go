@@ -0,0 +1,43 @@
+package buildkite
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAccessTokensService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/access-token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"uuid":"b63254c0-3271-4a98-8270-7cfbd6c2f14e","scopes":["read_builds","write_builds"]}`)
+	})
+
+	token, _, err := client.AccessTokens.Get()
+	if err != nil {
+		t.Errorf("AccessTokens.Get returned error: %v", err)
+	}
+
+	want := &AccessToken{UUID: String("b63254c0-3271-4a98-8270-7cfbd6c2f14e"), Scopes: []string{"read_builds", "write_builds"}}
+	if !reflect.DeepEqual(token, want) {
+		t.Errorf("AccessTokens.Get returned %+v, want %+v", token, want)
+	}
+}
+
+func TestAccessTokensService_Revoke(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/access-token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.AccessTokens.Revoke()
+	if err != nil {
+		t.Errorf("AccessTokens.Revoke returned error: %v", err)
+	}
+}
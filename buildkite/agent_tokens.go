@@ -0,0 +1,130 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AgentTokensService handles communication with the agent registration
+// token related methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agent-tokens
+type AgentTokensService struct {
+	client *Client
+}
+
+// CreateAgentToken - Create an agent registration token.
+type CreateAgentToken struct {
+	Description string `json:"description,omitempty"`
+}
+
+// AgentToken represents an agent registration token.
+//
+// Token holds the secret value and is only returned by Create; store it then,
+// as it can't be fetched again.
+type AgentToken struct {
+	ID          *string    `json:"id,omitempty"`
+	URL         *string    `json:"url,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Token       *string    `json:"token,omitempty"`
+	CreatedAt   *Timestamp `json:"created_at,omitempty"`
+	CreatedBy   *User      `json:"created_by,omitempty"`
+}
+
+// AgentTokenListOptions specifies the optional parameters to the
+// AgentTokensService.List method.
+type AgentTokenListOptions struct {
+	ListOptions
+}
+
+// List the agent registration tokens for a given organisation.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agent-tokens#list-tokens
+func (ats *AgentTokensService) List(org string, opt *AgentTokenListOptions) ([]AgentToken, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/agent-tokens", org)
+
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ats.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens := new([]AgentToken)
+	resp, err := ats.client.Do(req, tokens)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *tokens, resp, err
+}
+
+// Get fetches an agent registration token.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agent-tokens#get-a-token
+func (ats *AgentTokensService) Get(org string, id string) (*AgentToken, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/agent-tokens/%s", org, id)
+
+	req, err := ats.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := new(AgentToken)
+	resp, err := ats.client.Do(req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return token, resp, err
+}
+
+// Create - Creates an agent registration token for a given organisation.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agent-tokens#create-a-token
+func (ats *AgentTokensService) Create(org string, t *CreateAgentToken) (*AgentToken, *Response, error) {
+	if t == nil {
+		return nil, nil, errors.New("agent token must not be nil")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/agent-tokens", org)
+
+	req, err := ats.client.NewRequest("POST", u, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := new(AgentToken)
+	resp, err := ats.client.Do(req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return token, resp, err
+}
+
+// Revoke an agent registration token. Agents already registered with the
+// token stay connected, but no new agents can register with it.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agent-tokens#revoke-a-token
+func (ats *AgentTokensService) Revoke(org string, id string) (*Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/agent-tokens/%s", org, id)
+
+	req, err := ats.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ats.client.Do(req, nil)
+}
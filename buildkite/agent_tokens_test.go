@@ -0,0 +1,97 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAgentTokensService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agent-tokens", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":"123","description":"Default"},{"id":"1234","description":"Staging"}]`)
+	})
+
+	tokens, _, err := client.AgentTokens.List("my-great-org", nil)
+	if err != nil {
+		t.Errorf("AgentTokens.List returned error: %v", err)
+	}
+
+	want := []AgentToken{
+		{ID: String("123"), Description: String("Default")},
+		{ID: String("1234"), Description: String("Staging")},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("AgentTokens.List returned %+v, want %+v", tokens, want)
+	}
+}
+
+func TestAgentTokensService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agent-tokens/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123","description":"Default","created_at":"2015-03-04T02:26:54Z"}`)
+	})
+
+	token, _, err := client.AgentTokens.Get("my-great-org", "123")
+	if err != nil {
+		t.Errorf("AgentTokens.Get returned error: %v", err)
+	}
+
+	want := &AgentToken{ID: String("123"), Description: String("Default"), CreatedAt: NewTimestamp(referenceTime)}
+	if !reflect.DeepEqual(token, want) {
+		t.Errorf("AgentTokens.Get returned %+v, want %+v", token, want)
+	}
+}
+
+func TestAgentTokensService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreateAgentToken{Description: "Rotated"}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agent-tokens", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreateAgentToken)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"id":"123","description":"Rotated","token":"secret"}`)
+	})
+
+	token, _, err := client.AgentTokens.Create("my-great-org", input)
+	if err != nil {
+		t.Errorf("AgentTokens.Create returned error: %v", err)
+	}
+
+	want := &AgentToken{ID: String("123"), Description: String("Rotated"), Token: String("secret")}
+	if !reflect.DeepEqual(token, want) {
+		t.Errorf("AgentTokens.Create returned %+v, want %+v", token, want)
+	}
+}
+
+func TestAgentTokensService_Revoke(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agent-tokens/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.AgentTokens.Revoke("my-great-org", "123")
+	if err != nil {
+		t.Errorf("AgentTokens.Revoke returned error: %v", err)
+	}
+}
@@ -5,7 +5,12 @@
 
 package buildkite
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
 
 // AgentsService handles communication with the agent related
 // methods of the buildkite API.
@@ -38,9 +43,22 @@ type Agent struct {
 	Job *Job `json:"job,omitempty"`
 }
 
+// AgentStopOptions specifies the parameters to the AgentsService.Stop method.
+type AgentStopOptions struct {
+	// Cancel the job the agent is running rather than waiting for it to finish
+	Force bool `json:"force"`
+}
+
 // AgentListOptions specifies the optional parameters to the
 // AgentService.List method.
 type AgentListOptions struct {
+
+	// Filters the results by the given agent name
+	Name string `url:"name,omitempty"`
+
+	// Filters the results by the given hostname
+	Hostname string `url:"hostname,omitempty"`
+
 	ListOptions
 }
 
@@ -129,3 +147,84 @@ func (as *AgentsService) Delete(org string, id string) (*Response, error) {
 
 	return as.client.Do(req, nil)
 }
+
+// Stop an agent. Unless force is true the agent finishes its current job
+// before stopping.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/agents#stop-an-agent
+func (as *AgentsService) Stop(org string, id string, force bool) (*Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/agents/%s/stop", org, id)
+
+	req, err := as.client.NewRequest("PUT", u, &AgentStopOptions{Force: force})
+	if err != nil {
+		return nil, err
+	}
+
+	return as.client.Do(req, nil)
+}
+
+// AgentMetrics holds the job and agent counts of an organization, in total and
+// broken down by queue, as used to scale a fleet of agents.
+type AgentMetrics struct {
+	Agents       AgentCounts              `json:"agents"`
+	Jobs         JobCounts                `json:"jobs"`
+	Organization AgentMetricsOrganization `json:"organization"`
+}
+
+// AgentCounts holds the numbers of connected agents, in total and by queue.
+type AgentCounts struct {
+	Idle   int                    `json:"idle"`
+	Busy   int                    `json:"busy"`
+	Total  int                    `json:"total"`
+	Queues map[string]AgentCounts `json:"queues,omitempty"`
+}
+
+// JobCounts holds the numbers of unfinished jobs, in total and by queue.
+type JobCounts struct {
+	Scheduled int                  `json:"scheduled"`
+	Running   int                  `json:"running"`
+	Waiting   int                  `json:"waiting"`
+	Total     int                  `json:"total"`
+	Queues    map[string]JobCounts `json:"queues,omitempty"`
+}
+
+// AgentMetricsOrganization identifies the organization the metrics are for.
+type AgentMetricsOrganization struct {
+	Slug string `json:"slug"`
+}
+
+// Metrics fetches the agent and job counts of the organization an agent
+// token belongs to.
+//
+// Unlike the other AgentsService methods it takes an agent registration token
+// rather than an organization slug. The metrics are only served by the agent
+// API at AgentBaseURL, whose /v3/metrics endpoint has no organization in its
+// path: it authenticates with the registration token and reports on that
+// token's organization. The REST API has no metrics endpoint, and the
+// client's API token isn't accepted by the agent API, so an org slug couldn't
+// be used. The request is sent with only the agent token; the client's
+// authentication transport is bypassed.
+//
+// buildkite docs: https://buildkite.com/docs/apis/agent-api/metrics
+func (as *AgentsService) Metrics(agentToken string) (*AgentMetrics, *Response, error) {
+	if agentToken == "" {
+		return nil, nil, errors.New("agent token must not be empty")
+	}
+
+	u := as.client.AgentBaseURL.ResolveReference(&url.URL{Path: "v3/metrics"})
+
+	ctx := context.WithValue(context.Background(), httpClientKey{}, as.client.agentHTTPClient(agentToken))
+	req, err := as.client.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metrics := new(AgentMetrics)
+	resp, err := as.client.Do(req, metrics)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return metrics, resp, err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
 )
@@ -28,6 +29,47 @@ func TestAgentsService_List(t *testing.T) {
 	}
 }
 
+func TestAgentsService_List_filtered(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agents", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"name":     "my-agent",
+			"hostname": "ci-host-1",
+		})
+		fmt.Fprint(w, `[{"id":"123"}]`)
+	})
+
+	opt := &AgentListOptions{Name: "my-agent", Hostname: "ci-host-1"}
+	agents, _, err := client.Agents.List("my-great-org", opt)
+	if err != nil {
+		t.Errorf("Agents.List returned error: %v", err)
+	}
+
+	want := []Agent{{ID: String("123")}}
+	if !reflect.DeepEqual(agents, want) {
+		t.Errorf("Agents.List returned %+v, want %+v", agents, want)
+	}
+}
+
+func TestAgentsService_List_emptyFilters(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agents", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{})
+		fmt.Fprint(w, `[{"id":"123"}]`)
+	})
+
+	_, _, err := client.Agents.List("my-great-org", &AgentListOptions{})
+	if err != nil {
+		t.Errorf("Agents.List returned error: %v", err)
+	}
+}
+
 func TestAgentsService_Get(t *testing.T) {
 	setup()
 	defer teardown()
@@ -92,3 +134,95 @@ func TestAgentsService_Delete(t *testing.T) {
 		t.Errorf("Agents.Delete returned error: %v", err)
 	}
 }
+
+func TestAgentsService_Stop(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/agents/123/stop", func(w http.ResponseWriter, r *http.Request) {
+		v := new(AgentStopOptions)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "PUT")
+
+		if want := (&AgentStopOptions{Force: true}); !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+	})
+
+	_, err := client.Agents.Stop("my-great-org", "123", true)
+	if err != nil {
+		t.Errorf("Agents.Stop returned error: %v", err)
+	}
+}
+
+func TestAgentsService_Metrics(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.AgentBaseURL = client.BaseURL
+
+	mux.HandleFunc("/v3/metrics", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got, want := r.Header.Get("Authorization"), "Token my-agent-token"; got != want {
+			t.Errorf("Authorization header is %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{
+  "agents": {"idle": 1, "busy": 2, "total": 3, "queues": {"default": {"idle": 1, "busy": 2, "total": 3}}},
+  "jobs": {"scheduled": 4, "running": 2, "waiting": 1, "total": 7, "queues": {"default": {"scheduled": 4, "running": 2, "waiting": 1, "total": 7}}},
+  "organization": {"slug": "my-great-org"}
+}`)
+	})
+
+	metrics, _, err := client.Agents.Metrics("my-agent-token")
+	if err != nil {
+		t.Errorf("Agents.Metrics returned error: %v", err)
+	}
+
+	want := &AgentMetrics{
+		Agents: AgentCounts{Idle: 1, Busy: 2, Total: 3, Queues: map[string]AgentCounts{
+			"default": {Idle: 1, Busy: 2, Total: 3},
+		}},
+		Jobs: JobCounts{Scheduled: 4, Running: 2, Waiting: 1, Total: 7, Queues: map[string]JobCounts{
+			"default": {Scheduled: 4, Running: 2, Waiting: 1, Total: 7},
+		}},
+		Organization: AgentMetricsOrganization{Slug: "my-great-org"},
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		t.Errorf("Agents.Metrics returned %+v, want %+v", metrics, want)
+	}
+}
+
+func TestAgentsService_Metrics_authTransport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v3/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Token my-agent-token"; got != want {
+			t.Errorf("Authorization header is %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"organization": {"slug": "my-great-org"}}`)
+	})
+
+	// the API token transport matches every host, the agent API's included
+	transport := &TokenAuthTransport{APIToken: "my-api-token"}
+	c := NewClient(transport.Client())
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.AgentBaseURL = c.BaseURL
+	transport.APIHost = ""
+
+	if _, _, err := c.Agents.Metrics("my-agent-token"); err != nil {
+		t.Errorf("Agents.Metrics returned error: %v", err)
+	}
+
+	// the client's own requests still carry the API token
+	mux.HandleFunc("/v2/user", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer my-api-token"; got != want {
+			t.Errorf("Authorization header is %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+	if _, _, err := c.User.Get(); err != nil {
+		t.Errorf("User.Get returned error: %v", err)
+	}
+}
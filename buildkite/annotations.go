@@ -0,0 +1,104 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AnnotationsService handles communication with the annotation related
+// methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/annotations
+type AnnotationsService struct {
+	client *Client
+}
+
+// Annotation represents an annotation which has been stored from a build
+type Annotation struct {
+	ID        *string    `json:"id,omitempty"`
+	Context   *string    `json:"context,omitempty"`
+	Style     *string    `json:"style,omitempty"`
+	BodyHTML  *string    `json:"body_html,omitempty"`
+	CreatedAt *Timestamp `json:"created_at,omitempty"`
+	UpdatedAt *Timestamp `json:"updated_at,omitempty"`
+}
+
+// CreateAnnotation - Create an annotation on a build.
+type CreateAnnotation struct {
+	// Body is the annotation's content, in Markdown or HTML.
+	Body string `json:"body"`
+
+	// Optional fields
+
+	// Style is one of success, info, warning or error.
+	Style string `json:"style,omitempty"`
+
+	// Context identifies the annotation within the build, so later calls can
+	// replace or append to it.
+	Context string `json:"context,omitempty"`
+
+	// Append adds Body to the existing annotation with the same Context
+	// instead of replacing it.
+	Append bool `json:"append,omitempty"`
+}
+
+// AnnotationListOptions specifies the optional parameters to the
+// AnnotationsService.List method.
+type AnnotationListOptions struct {
+	ListOptions
+}
+
+// ListByBuild gets annotations for a specific build
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/annotations#list-annotations-for-a-build
+func (as *AnnotationsService) ListByBuild(org string, pipeline string, build string, opt *AnnotationListOptions) ([]Annotation, *Response, error) {
+	var u string
+
+	u = fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/annotations", org, pipeline, build)
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := as.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotations := new([]Annotation)
+	resp, err := as.client.Do(req, annotations)
+	if err != nil {
+		return nil, resp, err
+	}
+	return *annotations, resp, err
+}
+
+// Create adds an annotation to a build, or replaces or appends to the
+// annotation with the same context.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/annotations#create-an-annotation-on-a-build
+func (as *AnnotationsService) Create(org string, pipeline string, build string, a *CreateAnnotation) (*Annotation, *Response, error) {
+	if a == nil {
+		return nil, nil, errors.New("annotation must not be nil")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/annotations", org, pipeline, build)
+
+	req, err := as.client.NewRequest("POST", u, a)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation := new(Annotation)
+	resp, err := as.client.Do(req, annotation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return annotation, resp, err
+}
@@ -0,0 +1,82 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAnnotationsService_ListByBuild(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline/builds/awesome-build/annotations", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"page": "2",
+		})
+		fmt.Fprint(w, `[{
+			"id": "de0d4ab5-6360-467a-a34b-e5ef5db5320d",
+			"context": "test-summary",
+			"style": "error",
+			"body_html": "<p>2 tests failed</p>",
+			"created_at": "2015-03-04T02:26:54Z",
+			"updated_at": "2015-03-04T02:26:54Z"
+		}]`)
+	})
+
+	opt := &AnnotationListOptions{ListOptions: ListOptions{Page: 2}}
+	annotations, _, err := client.Annotations.ListByBuild("my-great-org", "my-great-pipeline", "awesome-build", opt)
+	if err != nil {
+		t.Errorf("ListByBuild returned error: %v", err)
+	}
+
+	want := []Annotation{{
+		ID:        String("de0d4ab5-6360-467a-a34b-e5ef5db5320d"),
+		Context:   String("test-summary"),
+		Style:     String("error"),
+		BodyHTML:  String("<p>2 tests failed</p>"),
+		CreatedAt: NewTimestamp(referenceTime),
+		UpdatedAt: NewTimestamp(referenceTime),
+	}}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("ListByBuild returned %+v, want %+v", annotations, want)
+	}
+}
+
+func TestAnnotationsService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreateAnnotation{Body: "Coverage is **87%**", Style: "info", Context: "coverage", Append: true}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline/builds/10/annotations", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreateAnnotation)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"id":"68aef727","context":"coverage","style":"info","body_html":"<p>Coverage is <strong>87%</strong></p>"}`)
+	})
+
+	annotation, _, err := client.Annotations.Create("my-great-org", "my-great-pipeline", "10", input)
+	if err != nil {
+		t.Errorf("Annotations.Create returned error: %v", err)
+	}
+
+	want := &Annotation{
+		ID:       String("68aef727"),
+		Context:  String("coverage"),
+		Style:    String("info"),
+		BodyHTML: String("<p>Coverage is <strong>87%</strong></p>"),
+	}
+	if !reflect.DeepEqual(annotation, want) {
+		t.Errorf("Annotations.Create returned %+v, want %+v", annotation, want)
+	}
+}
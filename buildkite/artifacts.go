@@ -6,6 +6,7 @@
 package buildkite
 
 import (
+	"context"
 	"fmt"
 	"io"
 )
@@ -66,6 +67,71 @@ func (as *ArtifactsService) ListByBuild(org string, pipeline string, build strin
 	return *artifacts, resp, err
 }
 
+// ListByJob gets artifacts for a specific job
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/artifacts#list-artifacts-for-a-job
+func (as *ArtifactsService) ListByJob(org string, pipeline string, build string, job string, opt *ArtifactListOptions) ([]Artifact, *Response, error) {
+	var u string
+
+	u = fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/artifacts", org, pipeline, build, job)
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := as.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artifacts := new([]Artifact)
+	resp, err := as.client.Do(req, artifacts)
+	if err != nil {
+		return nil, resp, err
+	}
+	return *artifacts, resp, err
+}
+
+// DownloadArtifact downloads an artifact into w. The API responds with a
+// redirect to the storage location of the artifact which is followed, and
+// the artifact is streamed into w rather than buffered in memory. The
+// ContentLength of the returned Response is that of the final, redirected
+// response and can be compared with Artifact.FileSize. Use GetDownloadURL to
+// get the storage location instead.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/artifacts#download-an-artifact
+func (as *ArtifactsService) DownloadArtifact(org string, pipeline string, build string, id string, w io.Writer) (*Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/artifacts/%s/download", org, pipeline, build, id)
+
+	return as.DownloadArtifactByURL(u, w)
+}
+
+// GetDownloadURL returns the URL an artifact is downloaded from, such as a
+// pre-signed S3 URL, without downloading it. The URL is only valid for a
+// limited time, so hand it to a browser or client promptly.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/artifacts#download-an-artifact
+func (as *ArtifactsService) GetDownloadURL(org string, pipeline string, build string, id string) (string, *Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/artifacts/%s/download", org, pipeline, build, id)
+
+	req, err := as.client.NewRequestWithContext(context.WithValue(context.Background(), noRedirectKey{}, true), "GET", u, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resp, err := as.client.Do(req, nil)
+	if err != nil {
+		return "", resp, err
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", resp, fmt.Errorf("artifact %s download responded with status %d rather than a redirect", id, resp.StatusCode)
+	}
+
+	return location, resp, nil
+}
+
 // DownloadArtifactByURL gets artifacts for a specific build
 //
 // buildkite API docs: https://buildkite.com/docs/api/artifacts#list-artifacts-for-a-build
@@ -74,6 +140,9 @@ func (as *ArtifactsService) DownloadArtifactByURL(url string, w io.Writer) (*Res
 	if err != nil {
 		return nil, err
 	}
+	// stop the transport decompressing artifacts stored gzipped, so the bytes
+	// written match Artifact.FileSize
+	req.Header.Set("Accept-Encoding", "identity")
 
 	resp, err := as.client.Do(req, w)
 	if err != nil {
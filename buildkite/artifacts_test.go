@@ -0,0 +1,145 @@
+package buildkite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestArtifactsService_ListByBuild(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"page":     "2",
+			"per_page": "10",
+		})
+		fmt.Fprint(w, `[{"id":"123","file_size":1024,"sha1sum":"abc"},{"id":"1234"}]`)
+	})
+
+	opt := &ArtifactListOptions{ListOptions: ListOptions{Page: 2, PerPage: 10}}
+	artifacts, _, err := client.Artifacts.ListByBuild("my-great-org", "sup-keith", "awesome-build", opt)
+	if err != nil {
+		t.Errorf("ListByBuild returned error: %v", err)
+	}
+
+	size := int64(1024)
+	want := []Artifact{{ID: String("123"), FileSize: &size, SHA1: String("abc")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("ListByBuild returned %+v, want %+v", artifacts, want)
+	}
+}
+
+func TestArtifactsService_ListByJob(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":"123","job_id":"awesome-job-id"}]`)
+	})
+
+	artifacts, _, err := client.Artifacts.ListByJob("my-great-org", "sup-keith", "awesome-build", "awesome-job-id", nil)
+	if err != nil {
+		t.Errorf("ListByJob returned error: %v", err)
+	}
+
+	want := []Artifact{{ID: String("123"), JobID: String("awesome-job-id")}}
+	if !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("ListByJob returned %+v, want %+v", artifacts, want)
+	}
+}
+
+func TestArtifactsService_DownloadArtifact(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/artifacts/123/download", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		http.Redirect(w, r, "/storage/artifact.txt", http.StatusFound)
+	})
+
+	mux.HandleFunc("/storage/artifact.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Header().Set("Content-Length", "14")
+		fmt.Fprint(w, "artifact bytes")
+	})
+
+	buf := new(bytes.Buffer)
+	resp, err := client.Artifacts.DownloadArtifact("my-great-org", "sup-keith", "awesome-build", "123", buf)
+	if err != nil {
+		t.Errorf("DownloadArtifact returned error: %v", err)
+	}
+
+	if got, want := buf.String(), "artifact bytes"; got != want {
+		t.Errorf("DownloadArtifact wrote %q, want %q", got, want)
+	}
+
+	if got, want := resp.ContentLength, int64(14); got != want {
+		t.Errorf("DownloadArtifact ContentLength is %d, want %d", got, want)
+	}
+}
+
+func TestArtifactsService_DownloadArtifact_gzip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var stored bytes.Buffer
+	gz := gzip.NewWriter(&stored)
+	gz.Write([]byte("artifact bytes"))
+	gz.Close()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/artifacts/123/download", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/storage/artifact.txt.gz", http.StatusFound)
+	})
+
+	mux.HandleFunc("/storage/artifact.txt.gz", func(w http.ResponseWriter, r *http.Request) {
+		// stored gzipped, as S3 serves an object uploaded with Content-Encoding
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(stored.Len()))
+		w.Write(stored.Bytes())
+	})
+
+	buf := new(bytes.Buffer)
+	resp, err := client.Artifacts.DownloadArtifact("my-great-org", "sup-keith", "awesome-build", "123", buf)
+	if err != nil {
+		t.Fatalf("DownloadArtifact returned error: %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), stored.Bytes()) {
+		t.Errorf("DownloadArtifact wrote %q, want the stored bytes %q", buf.Bytes(), stored.Bytes())
+	}
+
+	if got, want := resp.ContentLength, int64(stored.Len()); got != want {
+		t.Errorf("DownloadArtifact ContentLength is %d, want %d", got, want)
+	}
+}
+
+func TestArtifactsService_GetDownloadURL(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/artifacts/123/download", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		http.Redirect(w, r, "https://s3.amazonaws.com/artifacts/artifact.txt?X-Amz-Signature=abc", http.StatusFound)
+	})
+
+	u, resp, err := client.Artifacts.GetDownloadURL("my-great-org", "sup-keith", "awesome-build", "123")
+	if err != nil {
+		t.Errorf("GetDownloadURL returned error: %v", err)
+	}
+
+	if got, want := u, "https://s3.amazonaws.com/artifacts/artifact.txt?X-Amz-Signature=abc"; got != want {
+		t.Errorf("GetDownloadURL returned %q, want %q", got, want)
+	}
+
+	if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Errorf("GetDownloadURL returned status %d, want %d", got, want)
+	}
+}
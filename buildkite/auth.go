@@ -11,21 +11,42 @@ import (
 	"net/http"
 )
 
+// Authorization schemes for TokenAuthTransport.
+const (
+	TokenTypeBearer = "Bearer"
+	TokenTypeToken  = "Token"
+)
+
 // TokenAuthTransport manages injection of the API token for each request
 type TokenAuthTransport struct {
 	APIToken  string
 	APIHost   string
 	Transport http.RoundTripper
+
+	// TokenType is the authorization scheme the token is sent with, such as
+	// TokenTypeToken for integrations which require it. Defaults to
+	// TokenTypeBearer, which suits both API access tokens and OAuth tokens.
+	TokenType string
 }
 
 // RoundTrip invoked each time a request is made
 func (t TokenAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if req.URL.Host == t.APIHost || t.APIHost == "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.APIToken))
+		// a RoundTripper mustn't modify the request it's given
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", t.tokenType(), t.APIToken))
 	}
 	return t.transport().RoundTrip(req)
 }
 
+func (t TokenAuthTransport) tokenType() string {
+	if t.TokenType != "" {
+		return t.TokenType
+	}
+
+	return TokenTypeBearer
+}
+
 // Client builds a new http client.
 func (t *TokenAuthTransport) Client() *http.Client {
 	return &http.Client{Transport: t}
@@ -59,6 +80,7 @@ type BasicAuthTransport struct {
 // RoundTrip invoked each time a request is made
 func (bat BasicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if req.URL.Host == bat.APIHost || bat.APIHost == "" {
+		req = req.Clone(req.Context())
 		req.Header.Set("Authorization", fmt.Sprintf("Basic %s",
 			base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s",
 				bat.Username, bat.Password)))))
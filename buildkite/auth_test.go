@@ -0,0 +1,47 @@
+package buildkite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenAuthTransport(t *testing.T) {
+	var tests = []struct {
+		tokenType string
+		want      string
+	}{
+		{"", "Bearer abc"},
+		{TokenTypeBearer, "Bearer abc"},
+		{TokenTypeToken, "Token abc"},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization is %q, want %q", got, tt.want)
+			}
+		}))
+
+		transport := &TokenAuthTransport{APIToken: "abc", TokenType: tt.tokenType}
+		if _, err := transport.Client().Get(server.URL); err != nil {
+			t.Errorf("Get returned error: %v", err)
+		}
+
+		server.Close()
+	}
+}
+
+func TestTokenAuthTransport_otherHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization is %q, want none", got)
+		}
+	}))
+	defer server.Close()
+
+	transport := &TokenAuthTransport{APIToken: "abc", APIHost: "api.buildkite.com"}
+	if _, err := transport.Client().Get(server.URL); err != nil {
+		t.Errorf("Get returned error: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ package buildkite
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +20,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -25,36 +28,217 @@ import (
 )
 
 const (
-	defaultBaseURL = "https://api.buildkite.com/"
-	userAgent      = "go-buildkite/" + Version
-)
+	defaultBaseURL      = "https://api.buildkite.com/"
+	defaultAgentBaseURL = "https://agent.buildkite.com/"
+	userAgent           = "go-buildkite/" + Version
 
-var (
-	httpDebug = false
+	headerRateLimit     = "RateLimit-Limit"
+	headerRateRemaining = "RateLimit-Remaining"
+	headerRateReset     = "RateLimit-Reset"
 )
 
+// httpDebug is non-zero when request/response dumping is enabled; it's
+// accessed atomically as SetHttpDebug may be called while requests are in
+// flight.
+var httpDebug int32
+
 // Client - A Client manages communication with the buildkite API.
+//
+// A Client is safe for concurrent use by multiple goroutines: Do keeps all
+// per-request state, such as retry and rate limit details, on the stack and
+// in the returned Response. Configure it before sharing it, though; its
+// exported fields, and SetBaseURL, must not be changed while requests are
+// in flight.
 type Client struct {
 	// HTTP client used to communicate with the API.
 	client *http.Client
 
+	// API access token and its authorization scheme, applied by NewOpts once
+	// all options are processed.
+	token     string
+	tokenType string
+
 	// Base URL for API requests.  Defaults to the public buildkite API. BaseURL should
 	// always be specified with a trailing slash.
 	BaseURL *url.URL
 
-	// User agent used when communicating with the buildkite API.
+	// Base URL of the agent API, which serves AgentsService.Metrics and
+	// BuildsService.SetMetaData. The client's API token is never sent to it.
+	AgentBaseURL *url.URL
+
+	// User agent used when communicating with the buildkite API. Defaults to
+	// go-buildkite/<version>; set it to identify your own tool, e.g.
+	// "my-deploy-tool/1.2.3".
 	UserAgent string
 
+	// RetryConfig enables retrying of requests which fail with a rate limit or
+	// a transient server error. When nil only rate limited GET requests are
+	// retried, after the delay given by Retry-After or else an exponential
+	// backoff.
+	RetryConfig *RetryConfig
+
+	// Timeout bounds each call to Do, including any retries and reading the
+	// response body. Zero means no timeout beyond the request's context.
+	Timeout time.Duration
+
+	// DebugBody keeps a copy of each successful response body in
+	// Response.RawBody, e.g. to inspect fields the types here don't model.
+	// It buffers every body in memory, so leave it off in production.
+	DebugBody bool
+
+	// OnRequest, when set, is called before each attempt to send a request,
+	// including retries. The request must not be modified.
+	OnRequest func(*http.Request)
+
+	// OnResponse, when set, is called with each response received and the
+	// time taken to receive its headers, e.g. to log the status and latency.
+	// The response body must not be read.
+	//
+	// It takes the latency alongside the response, rather than being a plain
+	// func(*http.Response), because a hook can't time the request itself:
+	// requests may be retried and sent concurrently, so OnRequest and
+	// OnResponse calls can't be reliably paired up by the caller.
+	OnResponse func(*http.Response, time.Duration)
+
+	// Tracer, when set, starts a span around each call to Do.
+	Tracer Tracer
+
+	// limiter, when set, throttles requests, see SetRateLimiter.
+	limiter RateLimiter
+
 	// Services used for talking to different parts of the buildkite API.
+	AccessTokens  *AccessTokensService
 	Agents        *AgentsService
+	AgentTokens   *AgentTokensService
+	Annotations   *AnnotationsService
 	Artifacts     *ArtifactsService
 	Builds        *BuildsService
+	Clusters      *ClustersService
+	Emojis        *EmojisService
 	Jobs          *JobsService
 	Organizations *OrganizationsService
 	Pipelines     *PipelinesService
+	Teams         *TeamsService
 	User          *UserService
 }
 
+// RateLimiter throttles requests made by a Client. It's satisfied by
+// *rate.Limiter from golang.org/x/time/rate.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent, or returns an error if ctx is
+	// done first.
+	Wait(ctx context.Context) error
+}
+
+// SetRateLimiter makes Do wait on l before sending each request, including
+// retries, to stay under the API's rate limit instead of reacting to 429s.
+// A nil l turns throttling off.
+//
+// Buildkite allows 200 requests per minute per organization, e.g.
+//
+//	client.SetRateLimiter(rate.NewLimiter(rate.Every(time.Minute/200), 10))
+func (c *Client) SetRateLimiter(l RateLimiter) {
+	c.limiter = l
+}
+
+// RetryConfig specifies how Do retries requests that fail with a 429 Too Many
+// Requests or a transient 502, 503 or 504 server error.
+type RetryConfig struct {
+	// The maximum number of times a request is retried.
+	MaxRetries int
+
+	// Backoff returns the delay before the given retry attempt, starting at
+	// 1. It is not used for a 429 response carrying a Retry-After header.
+	// Defaults to an exponential backoff starting at half a second.
+	Backoff func(attempt int) time.Duration
+
+	// Retry POST requests too, which may not be safe to repeat. Only GET,
+	// HEAD, OPTIONS, PUT and DELETE requests are retried by default.
+	RetryPOST bool
+}
+
+// canRetry reports whether the request may be retried after receiving resp.
+func (rc *RetryConfig) canRetry(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return rc.RetryPOST
+	}
+	return false
+}
+
+// retryBackOff is a backoff.BackOff driven by a RetryConfig, preferring the
+// delay requested by the server when there is one.
+type retryBackOff struct {
+	config     *RetryConfig
+	attempt    int
+	retryAfter time.Duration
+}
+
+func (b *retryBackOff) Reset() {
+	b.attempt = 0
+	b.retryAfter = 0
+}
+
+func (b *retryBackOff) NextBackOff() time.Duration {
+	if b.attempt >= b.config.MaxRetries {
+		return backoff.Stop
+	}
+	b.attempt++
+
+	if b.retryAfter > 0 {
+		d := b.retryAfter
+		b.retryAfter = 0
+		return d
+	}
+	if b.config.Backoff != nil {
+		return b.config.Backoff(b.attempt)
+	}
+	return (500 * time.Millisecond) << uint(b.attempt-1)
+}
+
+// retryAfterBackOff waits for the delay requested by a rate limited
+// response's Retry-After header, when there is one, in place of the next delay
+// of the wrapped BackOff.
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next != backoff.Stop && b.retryAfter > 0 {
+		next = b.retryAfter
+	}
+	b.retryAfter = 0
+	return next
+}
+
+// parseRetryAfter parses the Retry-After header, which holds either a number
+// of seconds or an HTTP date. It returns zero if there is no usable value.
+func parseRetryAfter(r *http.Response) time.Duration {
+	v := r.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 // ListOptions specifies the optional parameters to various List methods that
 // support pagination.
 type ListOptions struct {
@@ -62,42 +246,175 @@ type ListOptions struct {
 	Page int `url:"page,omitempty"`
 
 	// For paginated result sets, the number of results to include per page.
+	// The API allows at most 100, so larger values are sent as 100.
 	PerPage int `url:"per_page,omitempty"`
 }
 
+// maxPerPage is the largest page size the API accepts; it rejects larger
+// ones with a 422.
+const maxPerPage = 100
+
+// ClientOpt configures a Client created with NewOpts.
+type ClientOpt func(*Client) error
+
+// NewOpts returns a new buildkite API client configured with the supplied
+// options, and is the preferred way to create a client:
+//
+//	client, err := buildkite.NewOpts(
+//		buildkite.WithToken(apiToken),
+//		buildkite.WithUserAgent("my-deploy-tool/1.2.3"),
+//	)
+//
+// Without WithHTTPClient a new http.Client is used, and without WithToken no
+// credentials are sent.
+func NewOpts(opts ...ClientOpt) (*Client, error) {
+	c := NewClient(&http.Client{})
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.token != "" {
+		hc := *c.client
+		hc.Transport = &TokenAuthTransport{APIToken: c.token, TokenType: c.tokenType, Transport: c.client.Transport}
+		c.client = &hc
+		c.token, c.tokenType = "", ""
+	}
+
+	c.setAPIHost()
+	return c, nil
+}
+
+// WithHTTPClient configures the client to make requests with httpClient, for
+// example to use a custom transport for tracing.
+func WithHTTPClient(httpClient *http.Client) ClientOpt {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("http client must not be nil")
+		}
+		c.client = httpClient
+		return nil
+	}
+}
+
+// WithToken configures the client to authenticate with the API access token,
+// wrapping the transport of the http client in a TokenAuthTransport.
+func WithToken(token string) ClientOpt {
+	return func(c *Client) error {
+		if token == "" {
+			return errors.New("Invalid token, empty string supplied")
+		}
+		c.token = token
+		return nil
+	}
+}
+
+// WithTokenType configures the authorization scheme the token supplied with
+// WithToken is sent with, see TokenAuthTransport.TokenType.
+func WithTokenType(tokenType string) ClientOpt {
+	return func(c *Client) error {
+		c.tokenType = tokenType
+		return nil
+	}
+}
+
+// WithBaseURL configures the client to talk to another buildkite API, see
+// Client.SetBaseURL.
+func WithBaseURL(urlStr string) ClientOpt {
+	return func(c *Client) error {
+		return c.SetBaseURL(urlStr)
+	}
+}
+
+// WithUserAgent configures the User-Agent sent with each request.
+func WithUserAgent(userAgent string) ClientOpt {
+	return func(c *Client) error {
+		c.UserAgent = userAgent
+		return nil
+	}
+}
+
 // NewClient returns a new buildkite API client. As API calls require authentication
 // you MUST supply a client which provides the required API key.
+//
+// NewOpts is preferred for new code.
 func NewClient(httpClient *http.Client) *Client {
 	baseURL, _ := url.Parse(defaultBaseURL)
+	agentBaseURL, _ := url.Parse(defaultAgentBaseURL)
 
 	c := &Client{
-		client:    httpClient,
-		BaseURL:   baseURL,
-		UserAgent: userAgent,
+		client:       httpClient,
+		BaseURL:      baseURL,
+		AgentBaseURL: agentBaseURL,
+		UserAgent:    userAgent,
 	}
+	c.AccessTokens = &AccessTokensService{c}
 	c.Agents = &AgentsService{c}
+	c.AgentTokens = &AgentTokensService{c}
+	c.Annotations = &AnnotationsService{c}
 	c.Artifacts = &ArtifactsService{c}
 	c.Builds = &BuildsService{c}
+	c.Clusters = &ClustersService{c}
+	c.Emojis = &EmojisService{c}
 	c.Jobs = &JobsService{c}
 	c.Organizations = &OrganizationsService{c}
 	c.Pipelines = &PipelinesService{c}
+	c.Teams = &TeamsService{c}
 	c.User = &UserService{c}
 
-	if c.client != nil {
-		if tokenAuth, ok := c.client.Transport.(*TokenAuthTransport); ok {
-			tokenAuth.APIHost = baseURL.Host
-		}
+	c.setAPIHost()
+	return c
+}
 
-		if basicAuth, ok := c.client.Transport.(*BasicAuthTransport); ok {
-			basicAuth.APIHost = baseURL.Host
-		}
+// SetBaseURL points the client at another buildkite API, such as a proxy or
+// a mock server. The URL must be absolute and end with a trailing slash, as
+// request paths are resolved relative to it.
+func (c *Client) SetBaseURL(urlStr string) error {
+	baseURL, err := url.Parse(urlStr)
+	if err != nil {
+		return err
+	}
+	if !baseURL.IsAbs() {
+		return fmt.Errorf("base URL %q must be absolute", urlStr)
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		return fmt.Errorf("base URL %q must have a trailing slash", urlStr)
+	}
+
+	c.BaseURL = baseURL
+	c.setAPIHost()
+	return nil
+}
+
+// setAPIHost restricts the authentication transports to the host of BaseURL,
+// so credentials are not sent to other hosts, such as when following redirects.
+func (c *Client) setAPIHost() {
+	if c.client == nil {
+		return
+	}
+
+	if tokenAuth, ok := c.client.Transport.(*TokenAuthTransport); ok {
+		tokenAuth.APIHost = c.BaseURL.Host
+	}
+
+	if basicAuth, ok := c.client.Transport.(*BasicAuthTransport); ok {
+		basicAuth.APIHost = c.BaseURL.Host
 	}
-	return c
 }
 
 // SetHttpDebug this enables global http request/response dumping for this API
 func SetHttpDebug(flag bool) {
-	httpDebug = flag
+	var v int32
+	if flag {
+		v = 1
+	}
+	atomic.StoreInt32(&httpDebug, v)
+}
+
+func debugEnabled() bool {
+	return atomic.LoadInt32(&httpDebug) != 0
 }
 
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
@@ -106,6 +423,12 @@ func SetHttpDebug(flag bool) {
 // specified, the value pointed to by body is JSON encoded and included as the
 // request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, urlStr, body)
+}
+
+// NewRequestWithContext creates an API request in the same way as NewRequest,
+// but the returned request is bound to ctx, which is honoured by Do.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	rel, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
@@ -125,11 +448,51 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	req.Header.Set("Content-Type", "application/json")
 
 	if c.UserAgent != "" {
-		req.Header.Add("User-Agent", c.UserAgent)
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	return req, nil
+}
+
+// NewUploadRequest creates an API request whose body is streamed from body
+// rather than JSON encoded, e.g. to send a large pipeline YAML file without
+// buffering it. The URL is resolved as for NewRequest, and the Content-Type
+// header is set to contentType.
+//
+// The length of body is known for a *bytes.Buffer, *bytes.Reader or
+// *strings.Reader; set the request's ContentLength for other readers, or the
+// body is sent chunked. Those readers can't be rewound either, so a request
+// with one of them isn't retried.
+func (c *Client) NewUploadRequest(method, urlStr string, body io.Reader, contentType string) (*http.Request, error) {
+	return c.NewUploadRequestWithContext(context.Background(), method, urlStr, body, contentType)
+}
+
+// NewUploadRequestWithContext creates an upload request in the same way as
+// NewUploadRequest, but the returned request is bound to ctx, which is
+// honoured by Do.
+func (c *Client) NewUploadRequestWithContext(ctx context.Context, method, urlStr string, body io.Reader, contentType string) (*http.Request, error) {
+	rel, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.BaseURL.ResolveReference(rel)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", contentType)
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
 	return req, nil
@@ -150,15 +513,62 @@ type Response struct {
 	PrevPage  int
 	FirstPage int
 	LastPage  int
+
+	// Rate is the rate limit state reported with the response.
+	Rate Rate
+
+	// ETag is the entity tag of the response, which can be passed to
+	// ContextWithETag to make a conditional request for the same resource.
+	ETag string
+
+	// NotModified is true when a conditional request got a 304 Not Modified,
+	// in which case nothing was decoded.
+	NotModified bool
+
+	// RawBody holds the response body as received when Client.DebugBody is
+	// set. The body of an error response is in ErrorResponse.RawBody instead.
+	RawBody []byte
+}
+
+// Rate represents the rate limit for the current client.
+type Rate struct {
+	// The number of requests per rate limit window.
+	Limit int
+
+	// The number of requests remaining in the current rate limit window.
+	Remaining int
+
+	// The time at which the current rate limit window resets.
+	Reset Timestamp
 }
 
 // newResponse creats a new Response for the provided http.Response.
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
 	response.populatePageValues()
+	response.Rate = parseRate(r)
+	response.ETag = r.Header.Get("ETag")
 	return response
 }
 
+// parseRate parses the rate limit headers. Buildkite reports the reset as
+// the number of seconds until the current window ends.
+func parseRate(r *http.Response) Rate {
+	var rate Rate
+	if limit := r.Header.Get(headerRateLimit); limit != "" {
+		rate.Limit, _ = strconv.Atoi(limit)
+	}
+	if remaining := r.Header.Get(headerRateRemaining); remaining != "" {
+		rate.Remaining, _ = strconv.Atoi(remaining)
+	}
+	if reset := r.Header.Get(headerRateReset); reset != "" {
+		if secs, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			rate.Reset = Timestamp{time.Now().Add(time.Duration(secs) * time.Second)}
+		}
+	}
+	return rate
+}
+
 // populatePageValues parses the HTTP Link response headers and populates the
 // various pagination link values in the Reponse.
 func (r *Response) populatePageValues() {
@@ -203,39 +613,179 @@ func (r *Response) populatePageValues() {
 	}
 }
 
+type etagKey struct{}
+
+// ContextWithETag returns a copy of ctx which makes requests sent with it
+// conditional on the resource having changed since the response carrying
+// etag, see Response.ETag. If it hasn't, Do returns a Response with
+// NotModified set and leaves the value to decode into untouched, e.g.
+//
+//	build, resp, err := client.Builds.GetWithContext(buildkite.ContextWithETag(ctx, etag), org, pipeline, id)
+//	if err == nil && resp.NotModified {
+//		// build is empty; the previously fetched build is still current
+//	}
+func ContextWithETag(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, etagKey{}, etag)
+}
+
+// noRedirectKey marks the context of a request whose redirect response should
+// be returned as is, rather than followed.
+type noRedirectKey struct{}
+
+// httpClientKey holds the *http.Client a request is sent with in place of the
+// client's own, as for the agent API, which mustn't see the API token.
+type httpClientKey struct{}
+
+// agentHTTPClient returns a copy of the client's HTTP client which sends
+// agentToken, and no other credentials, with each request. The authentication
+// transports of this package are replaced by the transport they wrap.
+func (c *Client) agentHTTPClient(agentToken string) *http.Client {
+	base := c.client.Transport
+	switch t := base.(type) {
+	case *TokenAuthTransport:
+		base = t.Transport
+	case TokenAuthTransport:
+		base = t.Transport
+	case *BasicAuthTransport, BasicAuthTransport:
+		base = nil
+	}
+
+	hc := *c.client
+	hc.Transport = &TokenAuthTransport{APIToken: agentToken, TokenType: TokenTypeToken, Transport: base}
+	return &hc
+}
+
 // Do sends an API request and returns the API response.  The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred.  If v implements the io.Writer
 // interface, the raw response body will be written to v, without attempting to
 // first decode it.
+//
+// If the request's context is cancelled or its deadline is exceeded, Do stops
+// retrying and returns the context's error unwrapped. This includes while
+// waiting to retry, e.g. for the delay given by a rate limited response's
+// Retry-After header, so a shutdown isn't held up by a pending retry.
+//
+// When Client.Tracer is set the request, including any retries, is wrapped in
+// a span.
 func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
+	if c.Tracer == nil {
+		return c.do(req, v)
+	}
+
+	ctx, span := c.Tracer.StartSpan(req.Context(), spanName(req))
+	defer span.End()
+
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", req.URL.String())
+
+	resp, err := c.do(req.WithContext(ctx), v)
+	if resp != nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return resp, err
+}
+
+func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
+	ctx := req.Context()
+	if etag, ok := ctx.Value(etagKey{}).(string); ok && etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	noRedirect, _ := ctx.Value(noRedirectKey{}).(bool)
+	hc := c.client
+	if override, ok := ctx.Value(httpClientKey{}).(*http.Client); ok {
+		hc = override
+	}
+	if noRedirect {
+		// copy the client, which may be shared, to change its redirect policy
+		nc := *hc
+		nc.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		hc = &nc
+	}
 	respCh := make(chan *http.Response, 1)
 
+	ra := &retryAfterBackOff{BackOff: backoff.NewExponentialBackOff()}
+	var b backoff.BackOff = ra
+	var rb *retryBackOff
+	if c.RetryConfig != nil {
+		rb = &retryBackOff{config: c.RetryConfig}
+		b = rb
+	}
+
 	op := func() error {
-		if httpDebug {
+		if debugEnabled() {
 			if dump, err := httputil.DumpRequest(req, true); err == nil {
 				fmt.Printf("DEBUG request uri=%s\n%s\n", req.URL, dump)
 			}
 		}
 
-		resp, err := c.client.Do(req)
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return backoff.Permanent(err)
+			}
+		}
+
+		if c.OnRequest != nil {
+			c.OnRequest(req)
+		}
+
+		var start time.Time
+		if c.OnResponse != nil {
+			start = time.Now()
+		}
+		resp, err := hc.Do(req)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return backoff.Permanent(ctxErr)
+			}
 			return backoff.Permanent(err)
 		}
 
-		if httpDebug {
+		if c.OnResponse != nil {
+			c.OnResponse(resp, time.Since(start))
+		}
+
+		if debugEnabled() {
 			if dump, err := httputil.DumpResponse(resp, true); err == nil {
 				fmt.Printf("DEBUG response uri=%s\n%s\n", req.URL, dump)
 			}
 		}
 
-		// Check for rate limiting response on idempotent requests
-		if req.Method == http.MethodGet && resp.StatusCode == http.StatusTooManyRequests {
-			errMsg := resp.Header.Get("Rate-Limit-Warning")
-			if errMsg == "" {
-				errMsg = "Too many requests, retry"
+		// a body which can't be rewound can't be sent again
+		rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+		if rb != nil {
+			if rewindable && rb.attempt < rb.config.MaxRetries && rb.config.canRetry(req, resp) {
+				rb.retryAfter = parseRetryAfter(resp)
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+
+				// rewind the body so it can be sent again
+				if req.GetBody != nil {
+					if req.Body, err = req.GetBody(); err != nil {
+						return backoff.Permanent(err)
+					}
+				}
+				return fmt.Errorf("%v %v: %d, retrying", req.Method, req.URL, resp.StatusCode)
 			}
-			return errors.New(errMsg)
+		} else if req.Method == http.MethodGet && resp.StatusCode == http.StatusTooManyRequests {
+			// Check for rate limiting response on idempotent requests
+			ra.retryAfter = parseRetryAfter(resp)
+			err := checkResponse(resp)
+			resp.Body.Close()
+			return err
 		}
 
 		respCh <- resp
@@ -243,63 +793,176 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 	}
 
 	notify := func(err error, delay time.Duration) {
-		if httpDebug {
+		if debugEnabled() {
 			fmt.Printf("DEBUG error %v, retry in %v\n", err, delay)
 		}
 	}
 
-	if err := backoff.RetryNotify(op, backoff.NewExponentialBackOff(), notify); err != nil {
+	if err := backoff.RetryNotify(op, backoff.WithContext(b, ctx), notify); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 
 	resp := <-respCh
 
+	_, isWriter := v.(io.Writer)
+	if resp.Header.Get("Content-Encoding") == "gzip" && !resp.Uncompressed && !isWriter {
+		// the transport only decompresses bodies when it asked for gzip
+		// itself, not when Accept-Encoding was set by the caller or a
+		// custom RoundTripper. Bodies written to an io.Writer, such as
+		// artifacts, are passed on as sent.
+		resp.Body = &gzipBody{body: resp.Body}
+	}
+
 	defer resp.Body.Close()
 	defer io.Copy(ioutil.Discard, resp.Body)
 
 	response := newResponse(resp)
 
+	if resp.StatusCode == http.StatusNotModified {
+		response.NotModified = true
+		return response, nil
+	}
+
+	if noRedirect && resp.StatusCode >= 300 && resp.StatusCode <= 399 {
+		return response, nil
+	}
+
 	if err := checkResponse(resp); err != nil {
 		// even though there was an error, we still return the response
 		// in case the caller wants to inspect it further
 		return response, err
 	}
 
+	var body io.Reader = resp.Body
+	if c.DebugBody {
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return response, err
+		}
+		response.RawBody = data
+		body = bytes.NewReader(data)
+	}
+
 	var err error
 
-	if v != nil {
+	if v != nil && !isEmptyResponse(resp) {
 		if w, ok := v.(io.Writer); ok {
-			_, err = io.Copy(w, resp.Body)
+			_, err = io.Copy(w, body)
 		} else {
-			err = json.NewDecoder(resp.Body).Decode(v)
+			err = json.NewDecoder(body).Decode(v)
+			if err == io.EOF {
+				err = nil // ignore EOF errors caused by empty response body
+			}
 		}
 	}
 
 	return response, err
 }
 
+// gzipBody decompresses a gzipped response body, closing the original body
+// when it's closed. The gzip header is only read on the first Read, so
+// responses without a body, such as a 304 or 204, which still carry
+// Content-Encoding: gzip are never decompressed.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(g.body)
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	if g.zr != nil {
+		g.zr.Close()
+	}
+	return g.body.Close()
+}
+
+// isEmptyResponse reports whether the response is known to carry no body.
+func isEmptyResponse(r *http.Response) bool {
+	switch r.StatusCode {
+	case http.StatusNoContent, http.StatusResetContent:
+		return true
+	}
+	return r.ContentLength == 0
+}
+
 // ErrorResponse provides a message.
 type ErrorResponse struct {
 	Response *http.Response // HTTP response that caused this error
 	Message  string         `json:"message"` // error message
+	Errors   []string       `json:"errors"`  // field errors, e.g. from a failed validation
 	RawBody  []byte         `json:"-"`       // Raw Response Body
 }
 
 func (r *ErrorResponse) Error() string {
+	if len(r.Errors) > 0 {
+		return fmt.Sprintf("%v %v: %d %v: %v",
+			r.Response.Request.Method, r.Response.Request.URL,
+			r.Response.StatusCode, r.Message, strings.Join(r.Errors, ", "))
+	}
 	return fmt.Sprintf("%v %v: %d %v",
 		r.Response.Request.Method, r.Response.Request.URL,
 		r.Response.StatusCode, r.Message)
 }
 
+// RateLimitError occurs when buildkite returns 429 Too Many Requests.
+type RateLimitError struct {
+	Response   *http.Response // HTTP response that caused this error
+	Message    string         `json:"message"` // error message
+	Rate       Rate           `json:"-"`       // rate limit state reported with the response
+	RetryAfter time.Duration  `json:"-"`       // delay requested by the Retry-After header, if any
+}
+
+func (r *RateLimitError) Error() string {
+	return fmt.Sprintf("%v %v: %d %v; rate limit resets at %v",
+		r.Response.Request.Method, r.Response.Request.URL,
+		r.Response.StatusCode, r.Message, r.Rate.Reset)
+}
+
 func checkResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
 	data, err := ioutil.ReadAll(r.Body)
+
+	if r.StatusCode == http.StatusTooManyRequests {
+		rateLimitError := &RateLimitError{
+			Response:   r,
+			Rate:       parseRate(r),
+			RetryAfter: parseRetryAfter(r),
+		}
+		if err == nil && data != nil {
+			json.Unmarshal(data, rateLimitError)
+		}
+		if rateLimitError.Message == "" {
+			rateLimitError.Message = r.Header.Get("Rate-Limit-Warning")
+		}
+		if rateLimitError.Message == "" {
+			rateLimitError.Message = "Too many requests, retry"
+		}
+		return rateLimitError
+	}
+
 	errorResponse := &ErrorResponse{Response: r, RawBody: data}
 	if err == nil && data != nil {
 		json.Unmarshal(data, errorResponse)
 	}
+	if errorResponse.Message == "" {
+		// the body was empty or not JSON
+		errorResponse.Message = http.StatusText(r.StatusCode)
+	}
 	return errorResponse
 }
 
@@ -321,10 +984,52 @@ func addOptions(s string, opt interface{}) (string, error) {
 		return s, err
 	}
 
+	omitZeroTimes(qs, v)
+
+	if perPage, err := strconv.Atoi(qs.Get("per_page")); err == nil && perPage > maxPerPage {
+		qs.Set("per_page", strconv.Itoa(maxPerPage))
+	}
+
 	u.RawQuery = qs.Encode()
 	return u.String(), nil
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
+// omitZeroTimes removes the parameters of the zero time.Time fields of the
+// options struct v. Older releases of go-querystring ignore omitempty on
+// time.Time fields, sending the zero time which the API treats as a real
+// bound.
+func omitZeroTimes(qs url.Values, v reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			omitZeroTimes(qs, v.Field(i))
+			continue
+		}
+		if f.Type != timeType || !v.Field(i).Interface().(time.Time).IsZero() {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("url"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		qs.Del(name)
+	}
+}
+
 // Int is a helper routine that allocates a new int value
 // to store v and returns a pointer to it, but unlike Int
 // its argument value is an int.
@@ -341,3 +1046,11 @@ func String(v string) *string {
 	*p = v
 	return p
 }
+
+// Bool is a helper routine that allocates a new bool value
+// to store v and returns a pointer to it.
+func Bool(v bool) *bool {
+	p := new(bool)
+	*p = v
+	return p
+}
@@ -1,12 +1,20 @@
 package buildkite
 
 import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var (
@@ -83,6 +91,75 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewOpts(t *testing.T) {
+	transport := &http.Transport{}
+	c, err := NewOpts(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithToken("abc"),
+		WithBaseURL("https://bk.internal.example.com/"),
+		WithUserAgent("my-deploy-tool/1.2.3"),
+	)
+	if err != nil {
+		t.Fatalf("NewOpts returned error: %v", err)
+	}
+
+	if got, want := c.BaseURL.String(), "https://bk.internal.example.com/"; got != want {
+		t.Errorf("NewOpts BaseURL is %v, want %v", got, want)
+	}
+	if got, want := c.UserAgent, "my-deploy-tool/1.2.3"; got != want {
+		t.Errorf("NewOpts UserAgent is %v, want %v", got, want)
+	}
+
+	auth, ok := c.client.Transport.(*TokenAuthTransport)
+	if !ok {
+		t.Fatalf("NewOpts transport is %T, want *TokenAuthTransport", c.client.Transport)
+	}
+	if auth.APIToken != "abc" || auth.APIHost != "bk.internal.example.com" || auth.Transport != transport {
+		t.Errorf("NewOpts transport is %+v, want token abc for bk.internal.example.com wrapping the supplied transport", auth)
+	}
+}
+
+func TestNewOpts_tokenType(t *testing.T) {
+	c, err := NewOpts(WithToken("abc"), WithTokenType(TokenTypeToken))
+	if err != nil {
+		t.Fatalf("NewOpts returned error: %v", err)
+	}
+
+	auth, ok := c.client.Transport.(*TokenAuthTransport)
+	if !ok {
+		t.Fatalf("NewOpts transport is %T, want *TokenAuthTransport", c.client.Transport)
+	}
+	if got, want := auth.TokenType, TokenTypeToken; got != want {
+		t.Errorf("NewOpts TokenType is %v, want %v", got, want)
+	}
+}
+
+func TestNewOpts_defaults(t *testing.T) {
+	c, err := NewOpts()
+	if err != nil {
+		t.Fatalf("NewOpts returned error: %v", err)
+	}
+
+	if got, want := c.BaseURL.String(), defaultBaseURL; got != want {
+		t.Errorf("NewOpts BaseURL is %v, want %v", got, want)
+	}
+	if got, want := c.UserAgent, userAgent; got != want {
+		t.Errorf("NewOpts UserAgent is %v, want %v", got, want)
+	}
+	if c.client == nil {
+		t.Error("NewOpts http client is nil")
+	}
+}
+
+func TestNewOpts_invalid(t *testing.T) {
+	if _, err := NewOpts(WithToken("")); err == nil {
+		t.Error("NewOpts with an empty token returned no error")
+	}
+	if _, err := NewOpts(WithBaseURL("https://bk.internal.example.com")); err == nil {
+		t.Error("NewOpts with no trailing slash on the base URL returned no error")
+	}
+}
+
 func TestNewRequest(t *testing.T) {
 	c := NewClient(nil)
 	inURL, outURL := "/foo", defaultBaseURL+"foo"
@@ -113,6 +190,119 @@ func TestNewRequest(t *testing.T) {
 	}
 }
 
+func TestNewUploadRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/upload", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if got, want := r.Header.Get("Content-Type"), "application/x-yaml"; got != want {
+			t.Errorf("Content-Type is %v, want %v", got, want)
+		}
+		if got, want := r.Header.Get("User-Agent"), client.UserAgent; got != want {
+			t.Errorf("User-Agent is %v, want %v", got, want)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if got, want := string(body), "steps:\n  - command: make\n"; got != want {
+			t.Errorf("Request body is %q, want %q", got, want)
+		}
+	})
+
+	req, err := client.NewUploadRequest("POST", "v2/upload", strings.NewReader("steps:\n  - command: make\n"), "application/x-yaml")
+	if err != nil {
+		t.Fatalf("NewUploadRequest returned error: %v", err)
+	}
+	if _, err := client.Do(req, nil); err != nil {
+		t.Errorf("Do returned error: %v", err)
+	}
+}
+
+func TestDo_retryUnrewindableBody(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client.RetryConfig = &RetryConfig{
+		MaxRetries: 2,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	}
+
+	// a MultiReader hides the underlying reader, so the body can't be rewound
+	body := io.MultiReader(strings.NewReader("artifact bytes"))
+	req, _ := client.NewUploadRequest("PUT", "/", body, "application/octet-stream")
+	client.Do(req, nil)
+	if got, want := calls, 1; got != want {
+		t.Errorf("Do made %d requests, want %d", got, want)
+	}
+}
+
+func TestNewRequest_customUserAgent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/user", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("User-Agent"), "my-deploy-tool/1.2.3"; got != want {
+			t.Errorf("User-Agent is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	client.UserAgent = "my-deploy-tool/1.2.3"
+	if _, _, err := client.User.Get(); err != nil {
+		t.Errorf("User.Get returned error: %v", err)
+	}
+}
+
+func TestSetBaseURL(t *testing.T) {
+	transport := &TokenAuthTransport{APIToken: "abc"}
+	c := NewClient(transport.Client())
+
+	if err := c.SetBaseURL("https://bk.internal.example.com/"); err != nil {
+		t.Fatalf("SetBaseURL returned error: %v", err)
+	}
+
+	req, _ := c.NewRequest("GET", "v2/builds", nil)
+	if got, want := req.URL.String(), "https://bk.internal.example.com/v2/builds"; got != want {
+		t.Errorf("NewRequest URL is %v, want %v", got, want)
+	}
+
+	if got, want := transport.APIHost, "bk.internal.example.com"; got != want {
+		t.Errorf("TokenAuthTransport.APIHost is %v, want %v", got, want)
+	}
+}
+
+func TestSetBaseURL_path(t *testing.T) {
+	c := NewClient(nil)
+
+	if err := c.SetBaseURL("https://proxy.example.com/buildkite/"); err != nil {
+		t.Fatalf("SetBaseURL returned error: %v", err)
+	}
+
+	req, _ := c.NewRequest("GET", "v2/builds", nil)
+	if got, want := req.URL.String(), "https://proxy.example.com/buildkite/v2/builds"; got != want {
+		t.Errorf("NewRequest URL is %v, want %v", got, want)
+	}
+}
+
+func TestSetBaseURL_invalid(t *testing.T) {
+	c := NewClient(nil)
+
+	for _, u := range []string{"https://bk.internal.example.com", "bk.internal.example.com/", ":"} {
+		if err := c.SetBaseURL(u); err == nil {
+			t.Errorf("SetBaseURL(%q) returned no error", u)
+		}
+	}
+
+	if got, want := c.BaseURL.String(), defaultBaseURL; got != want {
+		t.Errorf("BaseURL is %v after invalid SetBaseURL calls, want %v", got, want)
+	}
+}
+
 func TestResponse_populatePageValues(t *testing.T) {
 	r := http.Response{
 		Header: http.Header{
@@ -138,3 +328,686 @@ func TestResponse_populatePageValues(t *testing.T) {
 		t.Errorf("response.LastPage: %v, want %v", got, want)
 	}
 }
+
+func TestDo_pageValues(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/paged", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://api.buildkite.com/v2/builds?page=1&per_page=2>; rel="first",`+
+			` <https://api.buildkite.com/v2/builds?page=2&per_page=2>; rel="prev",`+
+			` <https://api.buildkite.com/v2/builds?page=4&per_page=2>; rel="next",`+
+			` <https://api.buildkite.com/v2/builds?page=9&per_page=2>; rel="last"`)
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/single", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	req, _ := client.NewRequest("GET", "/paged", nil)
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if resp.FirstPage != 1 || resp.PrevPage != 2 || resp.NextPage != 4 || resp.LastPage != 9 {
+		t.Errorf("Do returned pages first=%d prev=%d next=%d last=%d, want 1, 2, 4, 9",
+			resp.FirstPage, resp.PrevPage, resp.NextPage, resp.LastPage)
+	}
+
+	req, _ = client.NewRequest("GET", "/single", nil)
+	resp, err = client.Do(req, nil)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if resp.FirstPage != 0 || resp.PrevPage != 0 || resp.NextPage != 0 || resp.LastPage != 0 {
+		t.Errorf("Do returned pages first=%d prev=%d next=%d last=%d, want all zero",
+			resp.FirstPage, resp.PrevPage, resp.NextPage, resp.LastPage)
+	}
+}
+
+func TestDo_rateLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "200")
+		w.Header().Set(headerRateRemaining, "42")
+		w.Header().Set(headerRateReset, "30")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	before := time.Now()
+	req, _ := client.NewRequest("PUT", "/", nil)
+	resp, err := client.Do(req, nil)
+	if err == nil {
+		t.Error("Do returned no error, want an error")
+	}
+
+	if got, want := resp.Rate.Limit, 200; got != want {
+		t.Errorf("Rate.Limit is %v, want %v", got, want)
+	}
+	if got, want := resp.Rate.Remaining, 42; got != want {
+		t.Errorf("Rate.Remaining is %v, want %v", got, want)
+	}
+	if reset := resp.Rate.Reset.Time; reset.Before(before.Add(30*time.Second)) || reset.After(time.Now().Add(30*time.Second)) {
+		t.Errorf("Rate.Reset is %v, want about 30s from %v", reset, before)
+	}
+}
+
+func TestDo_retry(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	client.RetryConfig = &RetryConfig{
+		MaxRetries: 3,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	}
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	build := new(Build)
+	_, err := client.Do(req, build)
+	if err != nil {
+		t.Errorf("Do returned error: %v", err)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Do made %d requests, want %d", got, want)
+	}
+	if want := (&Build{ID: String("123")}); !reflect.DeepEqual(build, want) {
+		t.Errorf("Do decoded %+v, want %+v", build, want)
+	}
+}
+
+func TestDo_retryExhausted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client.RetryConfig = &RetryConfig{
+		MaxRetries: 2,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	}
+
+	req, _ := client.NewRequest("PUT", "/", &Build{ID: String("123")})
+	resp, err := client.Do(req, nil)
+	if err == nil {
+		t.Error("Do returned no error, want an error")
+	}
+	if got, want := resp.StatusCode, http.StatusServiceUnavailable; got != want {
+		t.Errorf("Do returned status %d, want %d", got, want)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("Do made %d requests, want %d", got, want)
+	}
+}
+
+func TestDo_retryPOST(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	client.RetryConfig = &RetryConfig{
+		MaxRetries: 2,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	}
+
+	req, _ := client.NewRequest("POST", "/", nil)
+	client.Do(req, nil)
+	if got, want := calls, 1; got != want {
+		t.Errorf("Do made %d requests, want %d", got, want)
+	}
+
+	calls = 0
+	client.RetryConfig.RetryPOST = true
+
+	req, _ = client.NewRequest("POST", "/", nil)
+	client.Do(req, nil)
+	if got, want := calls, 3; got != want {
+		t.Errorf("Do made %d requests with RetryPOST, want %d", got, want)
+	}
+}
+
+func TestDo_retryAfter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []time.Time
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, time.Now())
+		if len(calls) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if got, want := len(calls), 2; got != want {
+		t.Fatalf("Do made %d requests, want %d", got, want)
+	}
+	if wait := calls[1].Sub(calls[0]); wait < time.Second {
+		t.Errorf("Do retried after %v, want at least the Retry-After of 1s", wait)
+	}
+}
+
+func TestDo_retryAfterCancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	for _, rc := range []*RetryConfig{nil, {MaxRetries: 3}} {
+		client.RetryConfig = rc
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req, _ := client.NewRequestWithContext(ctx, "GET", "/", nil)
+
+		start := time.Now()
+		_, err := client.Do(req, nil)
+		cancel()
+
+		if err != context.DeadlineExceeded {
+			t.Errorf("Do with RetryConfig %+v returned error %v, want %v", rc, err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Do with RetryConfig %+v returned after %v, want it to stop waiting once the context is done", rc, elapsed)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	r := &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	if got, want := parseRetryAfter(r), 3*time.Second; got != want {
+		t.Errorf("parseRetryAfter returned %v, want %v", got, want)
+	}
+
+	r = &http.Response{Header: http.Header{}}
+	if got := parseRetryAfter(r); got != 0 {
+		t.Errorf("parseRetryAfter returned %v, want 0", got)
+	}
+}
+
+func TestDo_rateLimitError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateRemaining, "0")
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"message":"You have exceeded your rate limit"}`)
+	})
+
+	req, _ := client.NewRequest("PUT", "/", nil)
+	_, err := client.Do(req, nil)
+
+	rateLimitErr, ok := err.(*RateLimitError)
+	if !ok {
+		t.Fatalf("Do returned error %#v, want a *RateLimitError", err)
+	}
+	if got, want := rateLimitErr.Message, "You have exceeded your rate limit"; got != want {
+		t.Errorf("RateLimitError.Message is %q, want %q", got, want)
+	}
+	if got, want := rateLimitErr.RetryAfter, 5*time.Second; got != want {
+		t.Errorf("RateLimitError.RetryAfter is %v, want %v", got, want)
+	}
+	if got, want := rateLimitErr.Rate.Remaining, 0; got != want {
+		t.Errorf("RateLimitError.Rate.Remaining is %v, want %v", got, want)
+	}
+}
+
+func TestDo_errorResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message":"Validation Failed","errors":["Name can't be blank"]}`)
+	})
+
+	req, _ := client.NewRequest("POST", "/", nil)
+	_, err := client.Do(req, nil)
+
+	errorResponse, ok := err.(*ErrorResponse)
+	if !ok {
+		t.Fatalf("Do returned error %#v, want an *ErrorResponse", err)
+	}
+	if got, want := errorResponse.Message, "Validation Failed"; got != want {
+		t.Errorf("ErrorResponse.Message is %q, want %q", got, want)
+	}
+	if got, want := errorResponse.Errors, []string{"Name can't be blank"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ErrorResponse.Errors is %v, want %v", got, want)
+	}
+}
+
+func TestErrorResponse_Error(t *testing.T) {
+	u, _ := url.Parse("https://api.buildkite.com/v2/organizations/my-great-org/pipelines")
+	r := &http.Response{
+		Request:    &http.Request{Method: "POST", URL: u},
+		StatusCode: http.StatusUnprocessableEntity,
+	}
+
+	err := &ErrorResponse{Response: r, Message: "Validation Failed", Errors: []string{"Name can't be blank"}}
+	if got, want := err.Error(), "POST https://api.buildkite.com/v2/organizations/my-great-org/pipelines: 422 Validation Failed: Name can't be blank"; got != want {
+		t.Errorf("Error() is %q, want %q", got, want)
+	}
+
+	err = &ErrorResponse{Response: r, Message: "Validation Failed"}
+	if got, want := err.Error(), "POST https://api.buildkite.com/v2/organizations/my-great-org/pipelines: 422 Validation Failed"; got != want {
+		t.Errorf("Error() is %q, want %q", got, want)
+	}
+}
+
+func TestDo_errorResponseNotJSON(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `<html>bad gateway</html>`)
+	})
+
+	req, _ := client.NewRequest("POST", "/", nil)
+	resp, err := client.Do(req, nil)
+
+	errorResponse, ok := err.(*ErrorResponse)
+	if !ok {
+		t.Fatalf("Do returned error %#v, want an *ErrorResponse", err)
+	}
+	if got, want := errorResponse.Message, "Bad Gateway"; got != want {
+		t.Errorf("ErrorResponse.Message is %q, want %q", got, want)
+	}
+	if got, want := string(errorResponse.RawBody), `<html>bad gateway</html>`; got != want {
+		t.Errorf("ErrorResponse.RawBody is %q, want %q", got, want)
+	}
+	if resp == nil {
+		t.Error("Do returned no response alongside the error")
+	}
+}
+
+func TestDo_noContent(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusResetContent, http.StatusOK} {
+		setup()
+
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+
+		req, _ := client.NewRequest("DELETE", "/", nil)
+		body := new(Build)
+		resp, err := client.Do(req, body)
+		if err != nil {
+			t.Errorf("Do returned error for status %d: %v", status, err)
+		}
+		if got := resp.StatusCode; got != status {
+			t.Errorf("Do returned status %d, want %d", got, status)
+		}
+		if want := new(Build); !reflect.DeepEqual(body, want) {
+			t.Errorf("Do decoded %+v, want %+v", body, want)
+		}
+
+		teardown()
+	}
+}
+
+func TestDo_debugBody(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"123","unmodelled":true}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	build := new(Build)
+	resp, err := client.Do(req, build)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if resp.RawBody != nil {
+		t.Errorf("Do captured RawBody %q with DebugBody unset", resp.RawBody)
+	}
+
+	client.DebugBody = true
+
+	req, _ = client.NewRequest("GET", "/", nil)
+	build = new(Build)
+	resp, err = client.Do(req, build)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if got, want := string(resp.RawBody), `{"id":"123","unmodelled":true}`; got != want {
+		t.Errorf("Response.RawBody is %q, want %q", got, want)
+	}
+	if want := (&Build{ID: String("123")}); !reflect.DeepEqual(build, want) {
+		t.Errorf("Do decoded %+v, want %+v", build, want)
+	}
+}
+
+func TestDo_hooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	var requests []string
+	var statuses []int
+	client.OnRequest = func(r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+	}
+	client.OnResponse = func(r *http.Response, d time.Duration) {
+		statuses = append(statuses, r.StatusCode)
+		if d <= 0 {
+			t.Errorf("OnResponse called with duration %v, want > 0", d)
+		}
+	}
+
+	req, _ := client.NewRequest("PUT", "/", nil)
+	if _, err := client.Do(req, nil); err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	if want := []string{"PUT /"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("OnRequest called with %v, want %v", requests, want)
+	}
+	if want := []int{http.StatusAccepted}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("OnResponse called with %v, want %v", statuses, want)
+	}
+}
+
+func TestDo_etag(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	build := new(Build)
+	resp, err := client.Do(req, build)
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if got, want := resp.ETag, `"abc"`; got != want {
+		t.Errorf("Response.ETag is %q, want %q", got, want)
+	}
+	if resp.NotModified {
+		t.Error("Response.NotModified is true for an unconditional request")
+	}
+
+	req, _ = client.NewRequestWithContext(ContextWithETag(context.Background(), resp.ETag), "GET", "/", nil)
+	build = new(Build)
+	resp, err = client.Do(req, build)
+	if err != nil {
+		t.Fatalf("Do returned error for a conditional request: %v", err)
+	}
+	if !resp.NotModified {
+		t.Error("Response.NotModified is false for a 304")
+	}
+	if want := new(Build); !reflect.DeepEqual(build, want) {
+		t.Errorf("Do decoded %+v for a 304, want %+v", build, want)
+	}
+}
+
+// writeGzipped writes body to w gzipped, as a server honouring
+// Accept-Encoding: gzip would.
+func writeGzipped(t *testing.T, w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+}
+
+func TestDo_gzip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); !strings.Contains(got, "gzip") {
+			t.Errorf("Accept-Encoding header is %q, want gzip", got)
+		}
+		writeGzipped(t, w, `{"id":"123"}`)
+	})
+
+	for _, explicit := range []bool{false, true} {
+		req, _ := client.NewRequest("GET", "/", nil)
+		if explicit {
+			// the transport leaves the body compressed when the caller asks for gzip
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+
+		build := new(Build)
+		if _, err := client.Do(req, build); err != nil {
+			t.Fatalf("Do returned error (explicit Accept-Encoding %v): %v", explicit, err)
+		}
+		if want := (&Build{ID: String("123")}); !reflect.DeepEqual(build, want) {
+			t.Errorf("Do decoded %+v (explicit Accept-Encoding %v), want %+v", build, explicit, want)
+		}
+	}
+}
+
+func TestDo_gzipNoBody(t *testing.T) {
+	setup()
+	defer teardown()
+
+	for _, status := range []int{http.StatusNotModified, http.StatusNoContent} {
+		status := status
+		path := fmt.Sprintf("/%d", status)
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(status)
+		})
+
+		req, _ := client.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := client.Do(req, new(Build))
+		if err != nil {
+			t.Fatalf("Do returned error for a gzipped %d: %v", status, err)
+		}
+		if resp.StatusCode != status {
+			t.Errorf("Do returned status %d, want %d", resp.StatusCode, status)
+		}
+		if got, want := resp.NotModified, status == http.StatusNotModified; got != want {
+			t.Errorf("Response.NotModified is %v for a %d, want %v", got, status, want)
+		}
+	}
+}
+
+type testLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *testLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func TestDo_rateLimiter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	limiter := &testLimiter{}
+	client.SetRateLimiter(limiter)
+
+	for i := 0; i < 3; i++ {
+		req, _ := client.NewRequest("GET", "/", nil)
+		if _, err := client.Do(req, nil); err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+	}
+	if limiter.waits != 3 || requests != 3 {
+		t.Errorf("Do waited %d times for %d requests, want 3 and 3", limiter.waits, requests)
+	}
+
+	limiter.err = context.Canceled
+	req, _ := client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); err != context.Canceled {
+		t.Errorf("Do returned error %v, want %v", err, context.Canceled)
+	}
+	if requests != 3 {
+		t.Errorf("Do sent a request after the limiter failed")
+	}
+
+	client.SetRateLimiter(nil)
+	req, _ = client.NewRequest("GET", "/", nil)
+	if _, err := client.Do(req, nil); err != nil {
+		t.Errorf("Do returned error without a limiter: %v", err)
+	}
+}
+
+func TestDo_timeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	client.Timeout = 10 * time.Millisecond
+
+	req, _ := client.NewRequest("GET", "/", nil)
+	_, err := client.Do(req, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Do returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// TestClient_concurrentUse shares one client between many goroutines; run it
+// with -race to check Do keeps no shared mutable state.
+func TestClient_concurrentUse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer abc"; got != want {
+			t.Errorf("Authorization header is %q, want %q", got, want)
+		}
+		number := strings.TrimPrefix(r.URL.Path, "/v2/organizations/my-great-org/pipelines/sup-keith/builds/")
+		w.Header().Set(headerRateRemaining, number)
+		fmt.Fprintf(w, `{"id":"%s"}`, number)
+	})
+
+	c, err := NewOpts(WithToken("abc"), WithBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatalf("NewOpts returned error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				number := i*10 + j
+				build, resp, err := c.Builds.GetByNumber("my-great-org", "sup-keith", number)
+				if err != nil {
+					t.Errorf("Builds.GetByNumber returned error: %v", err)
+					return
+				}
+				if got, want := *build.ID, strconv.Itoa(number); got != want {
+					t.Errorf("Builds.GetByNumber returned build %s, want %s", got, want)
+				}
+				if got := resp.Rate.Remaining; got != number {
+					t.Errorf("Response.Rate.Remaining is %d, want %d", got, number)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAddOptions_zeroTime(t *testing.T) {
+	ts := time.Date(2016, time.March, 24, 1, 0, 0, 0, time.UTC)
+
+	u, err := addOptions("v2/builds", &BuildsListOptions{CreatedFrom: ts})
+	if err != nil {
+		t.Fatalf("addOptions returned error: %v", err)
+	}
+
+	if got, want := u, "v2/builds?created_from=2016-03-24T01%3A00%3A00Z"; got != want {
+		t.Errorf("addOptions returned %v, want %v", got, want)
+	}
+
+	// only zero time.Time fields are omitted, not strings which look like one
+	u, err = addOptions("v2/builds", &BuildsListOptions{Branch: "0001-01-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("addOptions returned error: %v", err)
+	}
+
+	if got, want := u, "v2/builds?branch=0001-01-01T00%3A00%3A00Z"; got != want {
+		t.Errorf("addOptions returned %v, want %v", got, want)
+	}
+}
+
+func TestAddOptions_perPage(t *testing.T) {
+	u, err := addOptions("v2/builds", &BuildsListOptions{ListOptions: ListOptions{Page: 2, PerPage: 500}})
+	if err != nil {
+		t.Fatalf("addOptions returned error: %v", err)
+	}
+
+	if got, want := u, "v2/builds?page=2&per_page=100"; got != want {
+		t.Errorf("addOptions returned %v, want %v", got, want)
+	}
+}
+
+func TestPointerHelpers(t *testing.T) {
+	if got := String("sup-keith"); got == nil || *got != "sup-keith" {
+		t.Errorf("String returned %v, want a pointer to %q", got, "sup-keith")
+	}
+	if got := Int(42); got == nil || *got != 42 {
+		t.Errorf("Int returned %v, want a pointer to %d", got, 42)
+	}
+	if got := Bool(true); got == nil || *got != true {
+		t.Errorf("Bool returned %v, want a pointer to %v", got, true)
+	}
+	if got := NewTimestamp(referenceTime); got == nil || !got.Time.Equal(referenceTime) {
+		t.Errorf("NewTimestamp returned %v, want a pointer to %v", got, referenceTime)
+	}
+}
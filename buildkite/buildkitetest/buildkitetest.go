@@ -0,0 +1,133 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildkitetest provides a fake buildkite API server for testing code
+// which uses the buildkite client.
+//
+//	srv := buildkitetest.NewServer()
+//	defer srv.Close()
+//
+//	srv.HandleFixture("GET", "/v2/organizations/my-org/pipelines", http.StatusOK, `[{"slug":"my-pipeline"}]`)
+//
+//	client := srv.NewClient()
+//	pipelines, _, err := client.Pipelines.List("my-org", nil)
+package buildkitetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/buildkite/go-buildkite/buildkite"
+)
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a fake buildkite API which records the requests it receives.
+// Register handlers on Mux, or canned responses with HandleFixture.
+type Server struct {
+	*httptest.Server
+
+	// Mux routes requests to the registered handlers.
+	Mux *http.ServeMux
+
+	mu       sync.Mutex
+	requests []Request
+	fixtures map[string]map[string]fixture // by path, then method
+}
+
+// fixture is a canned response registered with HandleFixture.
+type fixture struct {
+	status int
+	body   string
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{Mux: http.NewServeMux()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header,
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	s.Mux.ServeHTTP(w, r)
+}
+
+// NewClient returns a buildkite client which talks to the Server.
+func (s *Server) NewClient() *buildkite.Client {
+	client := buildkite.NewClient(s.Server.Client())
+	if err := client.SetBaseURL(s.URL + "/"); err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// HandleFixture responds to requests with the given method and path with the
+// status code and JSON body. Fixtures for several methods may be registered
+// on the same path; other methods get a 405 Method Not Allowed.
+func (s *Server) HandleFixture(method, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fixtures == nil {
+		s.fixtures = make(map[string]map[string]fixture)
+	}
+	byMethod, ok := s.fixtures[path]
+	if !ok {
+		byMethod = make(map[string]fixture)
+		s.fixtures[path] = byMethod
+		s.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			s.serveFixture(w, r, path)
+		})
+	}
+	byMethod[method] = fixture{status: status, body: body}
+}
+
+func (s *Server) serveFixture(w http.ResponseWriter, r *http.Request, path string) {
+	s.mu.Lock()
+	f, ok := s.fixtures[path][r.Method]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf(`{"message":"method %s not allowed"}`, r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.status)
+	fmt.Fprint(w, f.body)
+}
+
+// Requests returns the requests received by the Server so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
@@ -0,0 +1,82 @@
+package buildkitetest
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/buildkite"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.HandleFixture("GET", "/v2/organizations/my-great-org/pipelines", http.StatusOK, `[{"slug":"my-great-pipeline"}]`)
+
+	client := srv.NewClient()
+	pipelines, _, err := client.Pipelines.List("my-great-org", &buildkite.PipelineListOptions{
+		ListOptions: buildkite.ListOptions{Page: 2},
+	})
+	if err != nil {
+		t.Fatalf("Pipelines.List returned error: %v", err)
+	}
+
+	want := []buildkite.Pipeline{{Slug: buildkite.String("my-great-pipeline")}}
+	if !reflect.DeepEqual(pipelines, want) {
+		t.Errorf("Pipelines.List returned %+v, want %+v", pipelines, want)
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Server recorded %d requests, want 1", len(requests))
+	}
+	if got, want := requests[0].Method, "GET"; got != want {
+		t.Errorf("Request method is %v, want %v", got, want)
+	}
+	if got, want := requests[0].Path, "/v2/organizations/my-great-org/pipelines"; got != want {
+		t.Errorf("Request path is %v, want %v", got, want)
+	}
+	if got, want := requests[0].Query.Get("page"), "2"; got != want {
+		t.Errorf("Request page is %v, want %v", got, want)
+	}
+}
+
+func TestServer_wrongMethod(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.HandleFixture("DELETE", "/v2/organizations/my-great-org/pipelines/my-great-pipeline", http.StatusNoContent, "")
+
+	client := srv.NewClient()
+	_, _, err := client.Pipelines.Get("my-great-org", "my-great-pipeline")
+	if _, ok := err.(*buildkite.ErrorResponse); !ok {
+		t.Errorf("Pipelines.Get returned error %v, want an *ErrorResponse", err)
+	}
+}
+
+func TestServer_severalMethods(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	path := "/v2/organizations/my-great-org/pipelines/my-great-pipeline"
+	srv.HandleFixture("GET", path, http.StatusOK, `{"slug":"my-great-pipeline"}`)
+	srv.HandleFixture("DELETE", path, http.StatusNoContent, "")
+
+	client := srv.NewClient()
+	pipeline, _, err := client.Pipelines.Get("my-great-org", "my-great-pipeline")
+	if err != nil {
+		t.Fatalf("Pipelines.Get returned error: %v", err)
+	}
+	if want := (&buildkite.Pipeline{Slug: buildkite.String("my-great-pipeline")}); !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Get returned %+v, want %+v", pipeline, want)
+	}
+
+	resp, err := client.Pipelines.Delete("my-great-org", "my-great-pipeline")
+	if err != nil {
+		t.Fatalf("Pipelines.Delete returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Pipelines.Delete returned status %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
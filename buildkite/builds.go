@@ -6,7 +6,16 @@
 package buildkite
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,6 +27,24 @@ type BuildsService struct {
 	client *Client
 }
 
+// BuildState is the state of a build.
+type BuildState string
+
+// The states a build can be in. Build.State holds the same values as a
+// plain string, so compare with BuildState(*b.State) == BuildStatePassed.
+const (
+	BuildStateScheduled BuildState = "scheduled"
+	BuildStateRunning   BuildState = "running"
+	BuildStateFailing   BuildState = "failing"
+	BuildStateCanceling BuildState = "canceling"
+	BuildStatePassed    BuildState = "passed"
+	BuildStateFailed    BuildState = "failed"
+	BuildStateCanceled  BuildState = "canceled"
+	BuildStateSkipped   BuildState = "skipped"
+	BuildStateNotRun    BuildState = "not_run"
+	BuildStateBlocked   BuildState = "blocked"
+)
+
 // Author of a commit (used in CreateBuild)
 type Author struct {
 	Name  string `json:"name,omitempty"`
@@ -26,6 +53,8 @@ type Author struct {
 
 // CreateBuild - Create a build.
 type CreateBuild struct {
+	// Commit to build; BuildsService.Create sends "HEAD", the latest commit on
+	// Branch, when it's empty.
 	Commit  string `json:"commit"`
 	Branch  string `json:"branch"`
 	Message string `json:"message"`
@@ -40,6 +69,44 @@ type CreateBuild struct {
 	PullRequestRepository       string            `json:"pull_request_repository,omitempty"`
 }
 
+// validate checks the fields the API requires, so a bad build fails before
+// the request rather than with an opaque 422.
+func (cb *CreateBuild) validate() error {
+	if cb == nil {
+		return errors.New("buildkite: CreateBuild must not be nil")
+	}
+	if cb.Branch == "" {
+		return errors.New("buildkite: CreateBuild requires Branch")
+	}
+	if cb.PullRequestID != 0 && cb.PullRequestRepository == "" {
+		return errors.New("buildkite: CreateBuild with PullRequestID requires PullRequestRepository")
+	}
+	return nil
+}
+
+// NewPullRequestBuild returns a CreateBuild for pull request prID, opened from
+// branch of repo, with both set so the build is attributed to the pull
+// request.
+func NewPullRequestBuild(branch, commit string, prID int64, repo string) *CreateBuild {
+	return &CreateBuild{
+		Branch:                branch,
+		Commit:                commit,
+		PullRequestID:         prID,
+		PullRequestRepository: repo,
+	}
+}
+
+// WithCleanCheckout sets BUILDKITE_CLEAN_CHECKOUT=true in the build's
+// environment, so agents remove any existing checkout before running each
+// job. It returns cb to allow chaining.
+func (cb *CreateBuild) WithCleanCheckout() *CreateBuild {
+	if cb.Env == nil {
+		cb.Env = make(map[string]string)
+	}
+	cb.Env["BUILDKITE_CLEAN_CHECKOUT"] = "true"
+	return cb
+}
+
 // Creator represents who created a build
 type Creator struct {
 	AvatarURL string     `json:"avatar_url"`
@@ -56,24 +123,57 @@ type PullRequest struct {
 	Repository *string `json:"repository,omitempty"`
 }
 
+// BuildEnv is the environment of a build. Values are always decoded as
+// strings; numbers and booleans, which some builds report, are converted
+// with their JSON representation.
+type BuildEnv map[string]string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (e *BuildEnv) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*e = nil
+		return nil
+	}
+
+	env := make(BuildEnv, len(raw))
+	for k, v := range raw {
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			env[k] = str
+			continue
+		}
+		if string(v) == "null" {
+			env[k] = ""
+			continue
+		}
+		env[k] = string(v)
+	}
+	*e = env
+	return nil
+}
+
 // Build represents a build which has run in buildkite
 type Build struct {
-	ID          *string                `json:"id,omitempty"`
-	URL         *string                `json:"url,omitempty"`
-	WebURL      *string                `json:"web_url,omitempty"`
-	Number      *int                   `json:"number,omitempty"`
-	State       *string                `json:"state,omitempty"`
-	Blocked     *bool                  `json:"blocked,omitempty"`
-	Message     *string                `json:"message,omitempty"`
-	Commit      *string                `json:"commit,omitempty"`
-	Branch      *string                `json:"branch,omitempty"`
-	Env         map[string]interface{} `json:"env,omitempty"`
-	CreatedAt   *Timestamp             `json:"created_at,omitempty"`
-	ScheduledAt *Timestamp             `json:"scheduled_at,omitempty"`
-	StartedAt   *Timestamp             `json:"started_at,omitempty"`
-	FinishedAt  *Timestamp             `json:"finished_at,omitempty"`
-	MetaData    interface{}            `json:"meta_data,omitempty"`
-	Creator     *Creator               `json:"creator,omitempty"`
+	ID          *string           `json:"id,omitempty"`
+	URL         *string           `json:"url,omitempty"`
+	WebURL      *string           `json:"web_url,omitempty"`
+	Number      *int              `json:"number,omitempty"`
+	State       *string           `json:"state,omitempty"`
+	Blocked     *bool             `json:"blocked,omitempty"`
+	Message     *string           `json:"message,omitempty"`
+	Commit      *string           `json:"commit,omitempty"`
+	Branch      *string           `json:"branch,omitempty"`
+	Env         BuildEnv          `json:"env,omitempty"`
+	CreatedAt   *Timestamp        `json:"created_at,omitempty"`
+	ScheduledAt *Timestamp        `json:"scheduled_at,omitempty"`
+	StartedAt   *Timestamp        `json:"started_at,omitempty"`
+	FinishedAt  *Timestamp        `json:"finished_at,omitempty"`
+	MetaData    map[string]string `json:"meta_data,omitempty"`
+	Creator     *Creator          `json:"creator,omitempty"`
 
 	// jobs run during the build
 	Jobs []*Job `json:"jobs,omitempty"`
@@ -83,6 +183,171 @@ type Build struct {
 
 	// the pull request this build is associated with
 	PullRequest *PullRequest `json:"pull_request,omitempty"`
+
+	// the build this build is a rebuild of, if any
+	RebuiltFrom *RebuiltFrom `json:"rebuilt_from,omitempty"`
+}
+
+// RebuiltFrom identifies the build a rebuild was made from.
+type RebuiltFrom struct {
+	ID     *string `json:"id,omitempty"`
+	Number *int    `json:"number,omitempty"`
+	URL    *string `json:"url,omitempty"`
+}
+
+func (b Build) String() string {
+	return Stringify(b)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The API sends the
+// branch as a string, but a list of branches is also accepted, in case the
+// schema drifts, with Branch set to the first of them.
+func (b *Build) UnmarshalJSON(data []byte) error {
+	type build Build
+	aux := struct {
+		*build
+		Branch json.RawMessage `json:"branch,omitempty"`
+	}{build: (*build)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	// like encoding/json, leave Branch untouched when the key is missing
+	if len(aux.Branch) == 0 {
+		return nil
+	}
+	if string(aux.Branch) == "null" {
+		b.Branch = nil
+		return nil
+	}
+
+	var branch string
+	if err := json.Unmarshal(aux.Branch, &branch); err == nil {
+		b.Branch = &branch
+		return nil
+	}
+
+	var branches []string
+	if err := json.Unmarshal(aux.Branch, &branches); err != nil {
+		return fmt.Errorf("build branch must be a string or a list of strings, not %s", aux.Branch)
+	}
+	b.Branch = nil
+	if len(branches) > 0 {
+		b.Branch = &branches[0]
+	}
+	return nil
+}
+
+// IsFinished reports whether the build has reached a terminal state: passed,
+// failed, canceled, skipped, not_run or blocked.
+func (b *Build) IsFinished() bool {
+	if b.State == nil {
+		return false
+	}
+	switch BuildState(*b.State) {
+	case BuildStatePassed, BuildStateFailed, BuildStateCanceled, BuildStateSkipped, BuildStateNotRun, BuildStateBlocked:
+		return true
+	}
+	return false
+}
+
+// Duration returns the time the build took to run, from StartedAt to
+// FinishedAt. The bool is false, and the duration zero, if either is missing,
+// as it is while the build is still running.
+func (b *Build) Duration() (time.Duration, bool) {
+	if b.StartedAt == nil || b.FinishedAt == nil {
+		return 0, false
+	}
+	return b.FinishedAt.Sub(b.StartedAt.Time), true
+}
+
+// QueueDuration returns the time the build waited to start, from ScheduledAt
+// to StartedAt. The bool is false, and the duration zero, if either is missing.
+func (b *Build) QueueDuration() (time.Duration, bool) {
+	if b.ScheduledAt == nil || b.StartedAt == nil {
+		return 0, false
+	}
+	return b.StartedAt.Sub(b.ScheduledAt.Time), true
+}
+
+// FailedJobs returns the jobs of the build which failed or timed out, leaving
+// out those allowed to soft fail, see SoftFailedJobs.
+func (b *Build) FailedJobs() []*Job {
+	var failed []*Job
+	for _, job := range b.Jobs {
+		if job != nil && job.hasFailed() && (job.SoftFailed == nil || !*job.SoftFailed) {
+			failed = append(failed, job)
+		}
+	}
+	return failed
+}
+
+// SoftFailedJobs returns the jobs of the build which failed but were allowed
+// to soft fail, so didn't fail the build. Jobs which haven't finished yet
+// aren't included, even if they're marked as soft failed.
+func (b *Build) SoftFailedJobs() []*Job {
+	var failed []*Job
+	for _, job := range b.Jobs {
+		if job != nil && job.hasFailed() && job.SoftFailed != nil && *job.SoftFailed {
+			failed = append(failed, job)
+		}
+	}
+	return failed
+}
+
+// PendingQueues returns the agent queues that the build's scheduled jobs are
+// waiting on, sorted and without duplicates. A job's queue comes from its
+// queue= agent query rule; jobs without one wait on the "default" queue.
+func (b *Build) PendingQueues() []string {
+	seen := make(map[string]bool)
+	var queues []string
+	for _, job := range b.Jobs {
+		if job == nil || job.State == nil || JobState(*job.State) != JobStateScheduled {
+			continue
+		}
+
+		queue := "default"
+		for _, rule := range job.AgentQueryRules {
+			if strings.HasPrefix(rule, "queue=") {
+				queue = strings.TrimPrefix(rule, "queue=")
+			}
+		}
+
+		if !seen[queue] {
+			seen[queue] = true
+			queues = append(queues, queue)
+		}
+	}
+	sort.Strings(queues)
+	return queues
+}
+
+// IsRunning reports whether the build is running, including while it is
+// failing or being canceled.
+func (b *Build) IsRunning() bool {
+	if b.State == nil {
+		return false
+	}
+	switch BuildState(*b.State) {
+	case BuildStateRunning, BuildStateFailing, BuildStateCanceling:
+		return true
+	}
+	return false
+}
+
+// webBaseURL is the base of the URLs of the buildkite web interface.
+const webBaseURL = "https://buildkite.com/"
+
+// BuildWebURL returns the URL of a build on the buildkite website, as found in
+// Build.WebURL, e.g. to link to the build from a chat notification.
+func BuildWebURL(org, pipeline string, number int) string {
+	return fmt.Sprintf("%s%s/%s/builds/%d", webBaseURL, org, pipeline, number)
+}
+
+// BuildAPIURL returns the URL of a build in the public buildkite API, as found
+// in Build.URL.
+func BuildAPIURL(org, pipeline string, number int) string {
+	return fmt.Sprintf("%sv2/organizations/%s/pipelines/%s/builds/%d", defaultBaseURL, org, pipeline, number)
 }
 
 // BuildsListOptions specifies the optional parameters to the
@@ -101,46 +366,208 @@ type BuildsListOptions struct {
 	// Filters the results by builds finished on or after the given time
 	FinishedFrom time.Time `url:"finished_from,omitempty"`
 
+	// Filters the results by builds finished before the given time
+	FinishedTo time.Time `url:"finished_to,omitempty"`
+
 	// State of builds to list.  Possible values are: running, scheduled, passed,
-	// failed, canceled, skipped and not_run. Default is "".
+	// failed, canceled, skipped and not_run, see the BuildState constants.
+	// Default is "".
 	State []string `url:"state,brackets,omitempty"`
 
 	// Branch filter by the name of the branch. Default is "".
 	Branch string `url:"branch,omitempty"`
 
+	// Branches filters by any of several branches, sent as branch[]. Use it in
+	// place of Branch; setting both sends both filters.
+	Branches []string `url:"branch,brackets,omitempty"`
+
+	// BranchGlob filters by branches matching a pattern such as "release/*",
+	// using the syntax of path.Match. The API only matches exact names, so
+	// the builds are filtered as they're received, and every build on other
+	// branches still counts against the rate limit. Patterns with wildcards
+	// are only supported by the ListAll methods and IterateByPipeline, which
+	// fetch every page; the List methods return an error for them. A pattern
+	// without wildcards is sent as Branch.
+	BranchGlob string `url:"-"`
+
 	// Filters the results by builds for the specific commit SHA (full, not shortened). Default is "".
 	Commit string `url:"commit,omitempty"`
 
+	// Filters the results by the given build meta-data, e.g. {"release": "rc-1"}
+	MetaData MetaDataFilters `url:"meta_data,omitempty"`
+
+	// IncludeRetriedJobs adds the jobs which have since been retried to each
+	// Build.Jobs, alongside the jobs which replaced them.
+	IncludeRetriedJobs bool `url:"include_retried_jobs,omitempty"`
+
 	ListOptions
 }
 
+// MetaDataFilters filters builds by their meta-data, sent in the query string
+// as meta_data[key]=value.
+type MetaDataFilters map[string]string
+
+// EncodeValues implements the query.Encoder interface.
+func (m MetaDataFilters) EncodeValues(key string, v *url.Values) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.Add(fmt.Sprintf("%s[%s]", key, k), m[k])
+	}
+	return nil
+}
+
 // Cancel triggers a canel for the tagrget build
 //
 // buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#cancel-a-build
-func (bs *BuildsService) Cancel(org, pipeline, build string) (*Build, error) {
+func (bs *BuildsService) Cancel(org, pipeline, build string) (*Build, *Response, error) {
+	return bs.CancelWithContext(context.Background(), org, pipeline, build)
+}
+
+// CancelWithContext triggers a cancel for the target build using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#cancel-a-build
+func (bs *BuildsService) CancelWithContext(ctx context.Context, org, pipeline, build string) (*Build, *Response, error) {
 	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/cancel", org, pipeline, build)
-	req, err := bs.client.NewRequest("PUT", u, nil)
+	req, err := bs.client.NewRequestWithContext(ctx, "PUT", u, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	result := Build{}
-	_, err = bs.client.Do(req, &result)
+	resp, err := bs.client.Do(req, &result)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
-	return &result, nil
+	return &result, resp, nil
+}
+
+// CancelByBranch cancels every build of a pipeline on branch which is still
+// running or scheduled, such as after a force-push, and returns the cancelled
+// builds. Every page of such builds is listed first, and the Response of the
+// last list request is returned. The builds are then cancelled with up to four
+// requests in flight at a time. Failing cancels don't stop the others; their
+// errors are returned together as BuildErrors alongside the builds which were
+// cancelled.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#cancel-a-build
+func (bs *BuildsService) CancelByBranch(org, pipeline, branch string) ([]*Build, *Response, error) {
+	return bs.CancelByBranchWithContext(context.Background(), org, pipeline, branch)
+}
+
+// CancelByBranchWithContext cancels the running and scheduled builds of a
+// pipeline on branch using the supplied context, see CancelByBranch. Once ctx
+// is done no further cancels are started.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#cancel-a-build
+func (bs *BuildsService) CancelByBranchWithContext(ctx context.Context, org, pipeline, branch string) ([]*Build, *Response, error) {
+	opt := &BuildsListOptions{
+		Branch:      branch,
+		State:       []string{string(BuildStateRunning), string(BuildStateScheduled)},
+		ListOptions: ListOptions{PerPage: maxPerPage},
+	}
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline)
+	builds, resp, err := bs.listAll(ctx, u, opt)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	cancelled := make([]*Build, len(builds))
+	errs := make([]error, len(builds))
+
+	sem := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i, b := range builds {
+		if b.Number == nil {
+			errs[i] = errors.New("build has no number")
+			continue
+		}
+		wg.Add(1)
+		go func(i int, number int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			cancelled[i], _, errs[i] = bs.CancelWithContext(ctx, org, pipeline, strconv.Itoa(number))
+		}(i, *b.Number)
+	}
+	wg.Wait()
+
+	var result []*Build
+	var buildErrs BuildErrors
+	for i, err := range errs {
+		if err != nil {
+			buildErrs = append(buildErrs, newBuildError(i, &builds[i], err))
+			continue
+		}
+		result = append(result, cancelled[i])
+	}
+	if len(buildErrs) > 0 {
+		return result, resp, buildErrs
+	}
+
+	return result, resp, nil
 }
 
 // Create - Create a pipeline
 //
 // buildkite API docs: https://buildkite.com/docs/api/builds#create-a-build
 func (bs *BuildsService) Create(org string, pipeline string, b *CreateBuild) (*Build, *Response, error) {
+	return bs.CreateWithContext(context.Background(), org, pipeline, b)
+}
+
+// CreateWithContext creates a build using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#create-a-build
+func (bs *BuildsService) CreateWithContext(ctx context.Context, org string, pipeline string, b *CreateBuild) (*Build, *Response, error) {
+	return bs.create(ctx, org, pipeline, b, nil)
+}
+
+// BuildCreateOptions specifies the optional parameters to the
+// BuildsService.CreateWithOptions method.
+type BuildCreateOptions struct {
+	// IdempotencyKey is sent as the Idempotency-Key header. Retrying a create
+	// with the same key returns the build made by the first attempt rather
+	// than making a duplicate.
+	IdempotencyKey string
+}
+
+// CreateWithOptions creates a build, see Create, with the given options
+// using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#create-a-build
+func (bs *BuildsService) CreateWithOptions(ctx context.Context, org string, pipeline string, b *CreateBuild, opt *BuildCreateOptions) (*Build, *Response, error) {
+	return bs.create(ctx, org, pipeline, b, opt)
+}
+
+func (bs *BuildsService) create(ctx context.Context, org string, pipeline string, b *CreateBuild, opt *BuildCreateOptions) (*Build, *Response, error) {
+	if err := b.validate(); err != nil {
+		return nil, nil, err
+	}
+	if b.Commit == "" {
+		// build the latest commit on the branch, without changing the caller's struct
+		withHead := *b
+		withHead.Commit = "HEAD"
+		b = &withHead
+	}
+
 	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline)
 
-	req, err := bs.client.NewRequest("POST", u, b)
+	req, err := bs.client.NewRequestWithContext(ctx, "POST", u, b)
 	if err != nil {
 		return nil, nil, err
 	}
+	if opt != nil && opt.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", opt.IdempotencyKey)
+	}
 
 	build := new(Build)
 	resp, err := bs.client.Do(req, build)
@@ -151,13 +578,105 @@ func (bs *BuildsService) Create(org string, pipeline string, b *CreateBuild) (*B
 	return build, resp, err
 }
 
-// Get fetches a build.
+// Get fetches a build. id is the build's number within the pipeline, as
+// shown in Build.Number, not its UUID in Build.ID; use GetByNumber to pass
+// the number as an int.
 //
 // buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
 func (bs *BuildsService) Get(org string, pipeline string, id string) (*Build, *Response, error) {
+	return bs.GetWithContext(context.Background(), org, pipeline, id)
+}
+
+// GetByNumber fetches a build by its number within the pipeline.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetByNumber(org string, pipeline string, number int) (*Build, *Response, error) {
+	return bs.GetByNumberWithContext(context.Background(), org, pipeline, number)
+}
+
+// GetByNumberWithContext fetches a build by its number using the supplied
+// context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetByNumberWithContext(ctx context.Context, org string, pipeline string, number int) (*Build, *Response, error) {
+	return bs.GetWithContext(ctx, org, pipeline, strconv.Itoa(number))
+}
+
+// GetMany fetches several builds of a pipeline by number, with up to four
+// requests in flight at a time, and returns them in the order of numbers, so
+// the builds line up with numbers even when a number is repeated. Builds which
+// couldn't be fetched are left nil and their errors returned together as
+// BuildErrors, each with the index of its number. Once ctx is done no further
+// requests are started.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetMany(ctx context.Context, org string, pipeline string, numbers []int) ([]*Build, error) {
+	builds := make([]*Build, len(numbers))
+	errs := make([]error, len(numbers))
+
+	sem := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i, number := range numbers {
+		wg.Add(1)
+		go func(i int, number int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			builds[i], _, errs[i] = bs.get(ctx, org, pipeline, strconv.Itoa(number), nil)
+		}(i, number)
+	}
+	wg.Wait()
+
+	var buildErrs BuildErrors
+	for i, err := range errs {
+		if err != nil {
+			buildErrs = append(buildErrs, &BuildError{Index: i, Number: numbers[i], Err: err})
+		}
+	}
+	if len(buildErrs) > 0 {
+		return builds, buildErrs
+	}
+
+	return builds, nil
+}
+
+// GetWithContext fetches a build using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetWithContext(ctx context.Context, org string, pipeline string, id string) (*Build, *Response, error) {
+	return bs.get(ctx, org, pipeline, id, nil)
+}
+
+// BuildGetOptions specifies the optional parameters to the
+// BuildsService.GetWithOptions method.
+type BuildGetOptions struct {
+	// IncludeRetriedJobs adds the jobs which have since been retried to
+	// Build.Jobs, alongside the jobs which replaced them.
+	IncludeRetriedJobs bool `url:"include_retried_jobs,omitempty"`
+}
+
+// GetWithOptions fetches a build, see Get, with the given options using the
+// supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetWithOptions(ctx context.Context, org string, pipeline string, id string, opt *BuildGetOptions) (*Build, *Response, error) {
+	return bs.get(ctx, org, pipeline, id, opt)
+}
+
+func (bs *BuildsService) get(ctx context.Context, org string, pipeline string, id string, opt *BuildGetOptions) (*Build, *Response, error) {
 	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s", org, pipeline, id)
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	req, err := bs.client.NewRequest("GET", u, nil)
+	req, err := bs.client.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -171,100 +690,762 @@ func (bs *BuildsService) Get(org string, pipeline string, id string) (*Build, *R
 	return build, resp, err
 }
 
-// List the builds for the current user.
+// GetEnv fetches the fully resolved environment variables of a build.
 //
-// buildkite API docs: https://buildkite.com/docs/api/builds#list-all-builds
-func (bs *BuildsService) List(opt *BuildsListOptions) ([]Build, *Response, error) {
-	var u string
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#get-a-builds-environment-variables
+func (bs *BuildsService) GetEnv(org string, pipeline string, id string) (map[string]string, *Response, error) {
+	return bs.GetEnvWithContext(context.Background(), org, pipeline, id)
+}
 
-	u = fmt.Sprintf("v2/builds")
+// GetEnvWithContext fetches the environment variables of a build using the
+// supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#get-a-builds-environment-variables
+func (bs *BuildsService) GetEnvWithContext(ctx context.Context, org string, pipeline string, id string) (map[string]string, *Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/env", org, pipeline, id)
 
-	u, err := addOptions(u, opt)
+	req, err := bs.client.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := bs.client.NewRequest("GET", u, nil)
+	var env struct {
+		Env BuildEnv `json:"env"`
+	}
+	resp, err := bs.client.Do(req, &env)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
 
-	orgs := new([]Build)
-	resp, err := bs.client.Do(req, orgs)
+	return env.Env, resp, err
+}
+
+// ListAnnotations lists the annotations of a build. It's a shortcut for
+// AnnotationsService.ListByBuild.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/annotations#list-annotations-for-a-build
+func (bs *BuildsService) ListAnnotations(org string, pipeline string, build string, opt *ListOptions) ([]Annotation, *Response, error) {
+	var annotationOpt *AnnotationListOptions
+	if opt != nil {
+		annotationOpt = &AnnotationListOptions{ListOptions: *opt}
+	}
+
+	return bs.client.Annotations.ListByBuild(org, pipeline, build, annotationOpt)
+}
+
+// ListMetaData fetches the meta-data of a build, as set when it was created,
+// by its jobs or with SetMetaData.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) ListMetaData(org string, pipeline string, id string) (map[string]string, *Response, error) {
+	return bs.ListMetaDataWithContext(context.Background(), org, pipeline, id)
+}
+
+// ListMetaDataWithContext fetches the meta-data of a build using the supplied
+// context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) ListMetaDataWithContext(ctx context.Context, org string, pipeline string, id string) (map[string]string, *Response, error) {
+	build, resp, err := bs.GetWithContext(ctx, org, pipeline, id)
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return *orgs, resp, err
+	return build.MetaData, resp, err
+}
+
+// GetMetaData fetches the value of a single meta-data key of a build, see
+// ListMetaData. An error is returned if the build has no such key.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetMetaData(org string, pipeline string, id string, key string) (string, *Response, error) {
+	return bs.GetMetaDataWithContext(context.Background(), org, pipeline, id, key)
+}
+
+// GetMetaDataWithContext fetches the value of a single meta-data key of a
+// build using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#get-a-build
+func (bs *BuildsService) GetMetaDataWithContext(ctx context.Context, org string, pipeline string, id string, key string) (string, *Response, error) {
+	metaData, resp, err := bs.ListMetaDataWithContext(ctx, org, pipeline, id)
+	if err != nil {
+		return "", resp, err
+	}
+
+	value, ok := metaData[key]
+	if !ok {
+		return "", resp, fmt.Errorf("build %s has no meta-data %q", id, key)
+	}
+
+	return value, resp, nil
+}
+
+// SetMetaData sets a meta-data key of the build a job belongs to, as
+// buildkite-agent meta-data set does from within the job. The REST API can't
+// write meta-data, so unlike ListMetaData and GetMetaData this uses the agent
+// API at AgentBaseURL. That API identifies the build by one of its jobs, and
+// authenticates with the job's agent access token, the
+// BUILDKITE_AGENT_ACCESS_TOKEN of the job, rather than the client's API token.
+// The request is sent with only the access token.
+//
+// buildkite docs: https://buildkite.com/docs/pipelines/build-meta-data
+func (bs *BuildsService) SetMetaData(jobID string, accessToken string, key string, value string) (*Response, error) {
+	return bs.SetMetaDataWithContext(context.Background(), jobID, accessToken, key, value)
+}
+
+// SetMetaDataWithContext sets a meta-data key of the build a job belongs to
+// using the supplied context, see SetMetaData.
+//
+// buildkite docs: https://buildkite.com/docs/pipelines/build-meta-data
+func (bs *BuildsService) SetMetaDataWithContext(ctx context.Context, jobID string, accessToken string, key string, value string) (*Response, error) {
+	if jobID == "" {
+		return nil, errors.New("job ID must not be empty")
+	}
+	if accessToken == "" {
+		return nil, errors.New("agent access token must not be empty")
+	}
+	if key == "" {
+		return nil, errors.New("meta-data key must not be empty")
+	}
+
+	u := bs.client.AgentBaseURL.ResolveReference(&url.URL{Path: fmt.Sprintf("v3/jobs/%s/data/set", jobID)})
+
+	ctx = context.WithValue(ctx, httpClientKey{}, bs.client.agentHTTPClient(accessToken))
+	req, err := bs.client.NewRequestWithContext(ctx, "POST", u.String(), &struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}{key, value})
+	if err != nil {
+		return nil, err
+	}
+
+	return bs.client.Do(req, nil)
+}
+
+// List the builds for the current user.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-all-builds
+func (bs *BuildsService) List(opt *BuildsListOptions) ([]Build, *Response, error) {
+	return bs.ListWithContext(context.Background(), opt)
+}
+
+// ListWithContext lists the builds for the current user using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-all-builds
+func (bs *BuildsService) ListWithContext(ctx context.Context, opt *BuildsListOptions) ([]Build, *Response, error) {
+	var u string
+
+	u = fmt.Sprintf("v2/builds")
+
+	return bs.list(ctx, u, opt)
 }
 
 // ListByOrg lists the builds within the specified orginisation.
 //
 // buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-an-organization
 func (bs *BuildsService) ListByOrg(org string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	return bs.ListByOrgWithContext(context.Background(), org, opt)
+}
+
+// ListRunning lists the in-flight builds within the specified organisation,
+// those running or scheduled to run. The State option is replaced; any other
+// options are kept, and opt itself is left untouched.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-an-organization
+func (bs *BuildsService) ListRunning(org string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	return bs.ListRunningWithContext(context.Background(), org, opt)
+}
+
+// ListRunningWithContext lists the in-flight builds within the specified
+// organisation using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-an-organization
+func (bs *BuildsService) ListRunningWithContext(ctx context.Context, org string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	runningOpt := BuildsListOptions{}
+	if opt != nil {
+		runningOpt = *opt
+	}
+	runningOpt.State = []string{string(BuildStateRunning), string(BuildStateScheduled)}
+
+	return bs.ListByOrgWithContext(ctx, org, &runningOpt)
+}
+
+// ListByOrgWithContext lists the builds within the specified organisation using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-an-organization
+func (bs *BuildsService) ListByOrgWithContext(ctx context.Context, org string, opt *BuildsListOptions) ([]Build, *Response, error) {
 	var u string
 
 	u = fmt.Sprintf("v2/organizations/%s/builds", org)
 
+	return bs.list(ctx, u, opt)
+}
+
+// ListByPipeline lists the builds for a pipeline within the specified originisation.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPipeline(org string, pipeline string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	return bs.ListByPipelineWithContext(context.Background(), org, pipeline, opt)
+}
+
+// ListByPipelineWithContext lists the builds for a pipeline using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPipelineWithContext(ctx context.Context, org string, pipeline string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	var u string
+
+	u = fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline)
+
+	return bs.list(ctx, u, opt)
+}
+
+// maxConcurrentRequests bounds the requests made at once by the methods
+// which fan out over several pipelines or builds.
+const maxConcurrentRequests = 4
+
+// BuildError is the error of one build acted on by a method which acts on
+// several builds at once.
+type BuildError struct {
+	// Index is the position of the build among those acted on: in the numbers
+	// passed to GetMany, or in the builds found by CancelByBranch.
+	Index int
+
+	// ID and Number identify the build, when they're known.
+	ID     string
+	Number int
+
+	Err error
+}
+
+// newBuildError creates the BuildError of the build at index i, which may be
+// nil.
+func newBuildError(i int, b *Build, err error) *BuildError {
+	e := &BuildError{Index: i, Err: err}
+	if b != nil && b.ID != nil {
+		e.ID = *b.ID
+	}
+	if b != nil && b.Number != nil {
+		e.Number = *b.Number
+	}
+	return e
+}
+
+func (e *BuildError) Error() string {
+	switch {
+	case e.Number != 0:
+		return fmt.Sprintf("build %d: %v", e.Number, e.Err)
+	case e.ID != "":
+		return fmt.Sprintf("build %s: %v", e.ID, e.Err)
+	}
+	return fmt.Sprintf("build at index %d: %v", e.Index, e.Err)
+}
+
+// BuildErrors collects the errors of the methods which act on several builds
+// at once, ordered by BuildError.Index.
+type BuildErrors []*BuildError
+
+func (e BuildErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ListByPipelines lists the builds of several pipelines within the specified
+// organisation, newest first. One page of builds, per opt, is fetched from
+// each pipeline, with up to four requests in flight at a time. If any
+// pipeline fails to list, the first error is returned.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPipelines(org string, pipelines []string, opt *BuildsListOptions) ([]Build, error) {
+	return bs.ListByPipelinesWithContext(context.Background(), org, pipelines, opt)
+}
+
+// ListByPipelinesWithContext lists the builds of several pipelines using the
+// supplied context, see ListByPipelines. Once ctx is done no further requests
+// are started.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPipelinesWithContext(ctx context.Context, org string, pipelines []string, opt *BuildsListOptions) ([]Build, error) {
+	results := make([][]Build, len(pipelines))
+	errs := make([]error, len(pipelines))
+
+	sem := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i, pipeline := range pipelines {
+		wg.Add(1)
+		go func(i int, pipeline string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			results[i], _, errs[i] = bs.ListByPipelineWithContext(ctx, org, pipeline, opt)
+		}(i, pipeline)
+	}
+	wg.Wait()
+
+	var all []Build
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("listing builds of pipeline %s: %v", pipelines[i], err)
+		}
+		all = append(all, results[i]...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := all[i].CreatedAt, all[j].CreatedAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(b.Time)
+	})
+
+	return all, nil
+}
+
+// list fetches a page of the builds found at u, applying the supplied list
+// options, for the List methods. It refuses a BranchGlob with wildcards, as
+// filtering one page would leave it short while Response.NextPage still
+// pointed at more builds.
+func (bs *BuildsService) list(ctx context.Context, u string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	if opt != nil && strings.ContainsAny(opt.BranchGlob, `*?[\`) {
+		return nil, nil, fmt.Errorf("BranchGlob %q has wildcards, which need every page; use ListAll, ListAllByOrg, ListAllByPipeline or IterateByPipeline", opt.BranchGlob)
+	}
+	return bs.listPage(ctx, u, opt)
+}
+
+// listPage fetches a page of builds, filtering it by BranchGlob, so it may
+// hold fewer builds than were fetched.
+func (bs *BuildsService) listPage(ctx context.Context, u string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	var glob string
+	if opt != nil && opt.BranchGlob != "" {
+		if strings.ContainsAny(opt.BranchGlob, `*?[\`) {
+			if _, err := path.Match(opt.BranchGlob, ""); err != nil {
+				return nil, nil, fmt.Errorf("invalid BranchGlob %q: %v", opt.BranchGlob, err)
+			}
+			glob = opt.BranchGlob
+		} else {
+			exact := *opt
+			exact.Branch, exact.BranchGlob = opt.BranchGlob, ""
+			opt = &exact
+		}
+	}
+
 	u, err := addOptions(u, opt)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := bs.client.NewRequest("GET", u, nil)
+	req, err := bs.client.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	orgs := new([]Build)
-	resp, err := bs.client.Do(req, orgs)
+	builds := new([]Build)
+	resp, err := bs.client.Do(req, builds)
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return *orgs, resp, err
+	if glob != "" {
+		matched := (*builds)[:0]
+		for _, b := range *builds {
+			if b.Branch == nil {
+				continue
+			}
+			if ok, _ := path.Match(glob, *b.Branch); ok {
+				matched = append(matched, b)
+			}
+		}
+		*builds = matched
+	}
+
+	return *builds, resp, err
 }
 
-// ListByPipeline lists the builds for a pipeline within the specified originisation.
+// ListAll lists the builds for the current user, following the pagination
+// links until every page has been fetched.
 //
-// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
-func (bs *BuildsService) ListByPipeline(org string, pipeline string, opt *BuildsListOptions) ([]Build, *Response, error) {
-	var u string
+// All builds are accumulated in memory before returning, so consider narrowing
+// the results with the options, or paging with List, when there may be many
+// builds. The PerPage option sets the size of each request.
+func (bs *BuildsService) ListAll(opt *BuildsListOptions) ([]Build, error) {
+	return bs.ListAllWithContext(context.Background(), opt)
+}
 
-	u = fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline)
+// ListAllWithContext lists every build for the current user using the
+// supplied context. Once ctx is done no further pages are fetched.
+func (bs *BuildsService) ListAllWithContext(ctx context.Context, opt *BuildsListOptions) ([]Build, error) {
+	builds, _, err := bs.listAll(ctx, "v2/builds", opt)
+	return builds, err
+}
 
-	u, err := addOptions(u, opt)
+// ListAllByOrg lists the builds within the specified organisation, following
+// the pagination links until every page has been fetched. See ListAll for the
+// memory implications.
+func (bs *BuildsService) ListAllByOrg(org string, opt *BuildsListOptions) ([]Build, error) {
+	return bs.ListAllByOrgWithContext(context.Background(), org, opt)
+}
+
+// ListAllByOrgWithContext lists every build within the specified organisation
+// using the supplied context. Once ctx is done no further pages are fetched.
+func (bs *BuildsService) ListAllByOrgWithContext(ctx context.Context, org string, opt *BuildsListOptions) ([]Build, error) {
+	u := fmt.Sprintf("v2/organizations/%s/builds", org)
+
+	builds, _, err := bs.listAll(ctx, u, opt)
+	return builds, err
+}
+
+// ListAllByPipeline lists the builds for a pipeline, following the pagination
+// links until every page has been fetched. See ListAll for the memory
+// implications.
+func (bs *BuildsService) ListAllByPipeline(org string, pipeline string, opt *BuildsListOptions) ([]Build, error) {
+	return bs.ListAllByPipelineWithContext(context.Background(), org, pipeline, opt)
+}
+
+// ListAllByPipelineWithContext lists every build for a pipeline using the
+// supplied context. Once ctx is done no further pages are fetched.
+func (bs *BuildsService) ListAllByPipelineWithContext(ctx context.Context, org string, pipeline string, opt *BuildsListOptions) ([]Build, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline)
+
+	builds, _, err := bs.listAll(ctx, u, opt)
+	return builds, err
+}
+
+// ListByPullRequest lists the builds of a pipeline for the given pull request.
+// The API can't filter builds by pull request, so every build of the pipeline
+// matching opt is fetched, following the pagination links, and filtered by
+// Build.PullRequest. Narrow the search with opt, e.g. by Branch or
+// CreatedFrom, to reduce the requests made.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPullRequest(org string, pipeline string, prID int64, opt *BuildsListOptions) ([]Build, error) {
+	return bs.ListByPullRequestWithContext(context.Background(), org, pipeline, prID, opt)
+}
+
+// ListByPullRequestWithContext lists the builds of a pipeline for the given
+// pull request using the supplied context.
+//
+// buildkite API docs: https://buildkite.com/docs/api/builds#list-builds-for-a-pipeline
+func (bs *BuildsService) ListByPullRequestWithContext(ctx context.Context, org string, pipeline string, prID int64, opt *BuildsListOptions) ([]Build, error) {
+	builds, err := bs.ListAllByPipelineWithContext(ctx, org, pipeline, opt)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	id := strconv.FormatInt(prID, 10)
+
+	var matching []Build
+	for _, b := range builds {
+		if b.PullRequest != nil && b.PullRequest.ID != nil && *b.PullRequest.ID == id {
+			matching = append(matching, b)
+		}
+	}
+	return matching, nil
+}
+
+// listAll fetches every page of builds found at u, leaving opt untouched, and
+// returns the Response of the last page.
+func (bs *BuildsService) listAll(ctx context.Context, u string, opt *BuildsListOptions) ([]Build, *Response, error) {
+	pageOpt := BuildsListOptions{}
+	if opt != nil {
+		pageOpt = *opt
+	}
+
+	var all []Build
+	for {
+		builds, resp, err := bs.listPage(ctx, u, &pageOpt)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		all = append(all, builds...)
+
+		if resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		pageOpt.Page = resp.NextPage
+	}
+}
+
+// BuildIterator steps through a list of builds one at a time, fetching each
+// page as it's needed. Create one with BuildsService.IterateByPipeline.
+type BuildIterator struct {
+	bs  *BuildsService
+	ctx context.Context
+	u   string
+	opt BuildsListOptions
+
+	page []Build
+	done bool
+	err  error
+}
+
+// IterateByPipeline returns an iterator over the builds of a pipeline. Unlike
+// ListAllByPipeline only one page of builds is held in memory at a time. The
+// PerPage option sets the size of each request.
+//
+//	it := client.Builds.IterateByPipeline(org, pipeline, nil)
+//	for {
+//		build, err := it.Next()
+//		if err != nil || build == nil {
+//			break
+//		}
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (bs *BuildsService) IterateByPipeline(org string, pipeline string, opt *BuildsListOptions) *BuildIterator {
+	return bs.IterateByPipelineWithContext(context.Background(), org, pipeline, opt)
+}
+
+// IterateByPipelineWithContext returns an iterator over the builds of a
+// pipeline which fetches its pages using the supplied context. Once ctx is
+// done Next returns the context's error.
+func (bs *BuildsService) IterateByPipelineWithContext(ctx context.Context, org string, pipeline string, opt *BuildsListOptions) *BuildIterator {
+	it := &BuildIterator{
+		bs:  bs,
+		ctx: ctx,
+		u:   fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds", org, pipeline),
+	}
+	if opt != nil {
+		it.opt = *opt
+	}
+	return it
+}
+
+// Next returns the next build, fetching the next page when the current one
+// is used up. It returns nil and a nil error once every build has been
+// returned. After an error Next keeps returning that error, as does Err.
+func (it *BuildIterator) Next() (*Build, error) {
+	for len(it.page) == 0 {
+		if it.err != nil || it.done {
+			return nil, it.err
+		}
+
+		builds, resp, err := it.bs.listPage(it.ctx, it.u, &it.opt)
+		if err != nil {
+			it.err = err
+			return nil, err
+		}
+
+		it.page = builds
+		if resp.NextPage == 0 {
+			it.done = true
+		}
+		it.opt.Page = resp.NextPage
 	}
 
-	req, err := bs.client.NewRequest("GET", u, nil)
+	build := &it.page[0]
+	it.page = it.page[1:]
+	return build, nil
+}
+
+// Err returns the error, if any, which stopped the iteration.
+func (it *BuildIterator) Err() error {
+	return it.err
+}
+
+// Unblock unblocks the single blocked job of a build and returns the build
+// as it stands afterwards.
+//
+// A blocked job is a block step, a job of type "manual" in state "blocked".
+// If the build has no blocked job, or more than one, an error is returned and
+// nothing is unblocked; use JobsService.UnblockJob to choose between several.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#unblock-a-job
+func (bs *BuildsService) Unblock(org, pipeline, build string) (*Build, *Response, error) {
+	return bs.UnblockWithContext(context.Background(), org, pipeline, build)
+}
+
+// UnblockWithContext unblocks the single blocked job of a build using the
+// supplied context, see Unblock.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#unblock-a-job
+func (bs *BuildsService) UnblockWithContext(ctx context.Context, org, pipeline, build string) (*Build, *Response, error) {
+	b, resp, err := bs.GetWithContext(ctx, org, pipeline, build)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
+	}
+
+	var blocked []*Job
+	for _, job := range b.Jobs {
+		if job != nil && job.Type != nil && *job.Type == "manual" && job.State != nil && JobState(*job.State) == JobStateBlocked {
+			blocked = append(blocked, job)
+		}
 	}
 
-	orgs := new([]Build)
-	resp, err := bs.client.Do(req, orgs)
+	switch len(blocked) {
+	case 0:
+		return nil, resp, fmt.Errorf("build %s has no blocked jobs", build)
+	case 1:
+	default:
+		return nil, resp, fmt.Errorf("build %s has %d blocked jobs, unblock one with JobsService.UnblockJob", build, len(blocked))
+	}
+	if blocked[0].ID == nil {
+		return nil, resp, fmt.Errorf("blocked job of build %s has no ID", build)
+	}
+
+	_, resp, err = bs.client.Jobs.unblockJob(ctx, org, pipeline, build, *blocked[0].ID, &JobUnblockOptions{})
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return *orgs, resp, err
+	return bs.GetWithContext(ctx, org, pipeline, build)
+}
+
+// WaitForBuild polls the build every interval until it reaches a terminal
+// state, see Build.IsFinished, and returns it. If ctx is done first, the most
+// recently fetched build is returned along with the context's error. The
+// interval must be positive.
+func (bs *BuildsService) WaitForBuild(ctx context.Context, org, pipeline, build string, interval time.Duration) (*Build, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive, not %v", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *Build
+	for {
+		b, _, err := bs.GetWithContext(ctx, org, pipeline, build)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return last, ctxErr
+			}
+			return nil, err
+		}
+		last = b
+
+		if b.IsFinished() {
+			return b, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return b, ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
-// Rebuild triggers a rebuild for the target build
+// jobPollInterval is how often CreateAndWaitForJob polls the build.
+var jobPollInterval = 2 * time.Second
+
+// CreateAndWaitForJob creates a build and polls it until the job for the step
+// with key stepKey is runnable, that is scheduled to run on an agent or
+// further along, returning the build and that job. The job may not have
+// started yet when it's returned; poll it for the running state if needed.
+// Jobs still waiting on other steps, blocked or limited aren't matched.
+//
+// An error is returned, along with the build and job, if the job ends without
+// running, e.g. because it was skipped, canceled or expired, and if the build
+// finishes without running the step. If ctx is done first, the most recently
+// fetched build is returned along with the context's error.
+func (bs *BuildsService) CreateAndWaitForJob(ctx context.Context, org, pipeline string, b *CreateBuild, stepKey string) (*Build, *Job, error) {
+	build, _, err := bs.CreateWithContext(ctx, org, pipeline, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if build.Number == nil {
+		return build, nil, errors.New("created build has no number")
+	}
+	number := strconv.Itoa(*build.Number)
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, job := range build.Jobs {
+			if job == nil || job.StepKey == nil || *job.StepKey != stepKey {
+				continue
+			}
+			scheduled, err := jobHasBeenScheduled(job)
+			if err != nil {
+				return build, job, fmt.Errorf("build %s step %q: %v", number, stepKey, err)
+			}
+			if scheduled {
+				return build, job, nil
+			}
+		}
+		if build.IsFinished() {
+			return build, nil, fmt.Errorf("build %s finished without running step %q", number, stepKey)
+		}
+
+		select {
+		case <-ctx.Done():
+			return build, nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, _, err := bs.GetWithContext(ctx, org, pipeline, number)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return build, nil, ctxErr
+			}
+			return build, nil, err
+		}
+		build = next
+	}
+}
+
+// jobHasBeenScheduled reports whether job is runnable: scheduled to run on an
+// agent, or further along, whether running or finished. A scheduled job may
+// not have started yet. It returns an error if the job reached a terminal
+// state without running.
+func jobHasBeenScheduled(job *Job) (bool, error) {
+	if job.State == nil {
+		return false, nil
+	}
+	switch s := JobState(*job.State); s {
+	case JobStateScheduled, JobStateAssigned, JobStateAccepted, JobStateRunning,
+		JobStatePassed, JobStateFailed, JobStateCanceling, JobStateTimingOut, JobStateTimedOut:
+		return true, nil
+	case JobStateCanceled:
+		// jobs are canceled both while running and before they start
+		if job.StartedAt != nil {
+			return true, nil
+		}
+		return false, errors.New("job was canceled without running")
+	case JobStateSkipped, JobStateBroken, JobStateExpired,
+		JobStateWaitingFailed, JobStateBlockedFailed, JobStateUnblockedFailed:
+		return false, fmt.Errorf("job ended %s without running", s)
+	}
+	return false, nil
+}
+
+// Rebuild triggers a rebuild for the target build. The returned build is
+// the new build, with its own ID and Number, and RebuiltFrom identifying the
+// target build; poll the new build to follow the rebuild. The API takes no
+// options for a rebuild, which reuses the target build's commit, branch and
+// environment.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#rebuild-a-build
+func (bs *BuildsService) Rebuild(org, pipeline, build string) (*Build, *Response, error) {
+	return bs.RebuildWithContext(context.Background(), org, pipeline, build)
+}
+
+// RebuildWithContext triggers a rebuild for the target build using the
+// supplied context, and returns the new build, see Rebuild.
 //
 // buildkite API docs: https://buildkite.com/docs/apis/rest-api/builds#rebuild-a-build
-func (bs *BuildsService) Rebuild(org, pipeline, build string) (*Build, error) {
+func (bs *BuildsService) RebuildWithContext(ctx context.Context, org, pipeline, build string) (*Build, *Response, error) {
 	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/rebuild", org, pipeline, build)
-	req, err := bs.client.NewRequest("PUT", u, nil)
+	req, err := bs.client.NewRequestWithContext(ctx, "PUT", u, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	result := Build{}
-	_, err = bs.client.Do(req, &result)
+	resp, err := bs.client.Do(req, &result)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
-	return &result, nil
+	return &result, resp, nil
 }
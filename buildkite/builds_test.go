@@ -1,6 +1,7 @@
 package buildkite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -21,7 +22,7 @@ func TestBuildsService_Cancel(t *testing.T) {
 }`)
 	})
 
-	build, err := client.Builds.Cancel("my-great-org", "sup-keith", "1")
+	build, _, err := client.Builds.Cancel("my-great-org", "sup-keith", "1")
 	if err != nil {
 		t.Errorf("Cancel returned error: %v", err)
 	}
@@ -32,6 +33,447 @@ func TestBuildsService_Cancel(t *testing.T) {
 	}
 }
 
+func TestBuildsService_CancelByBranch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.FormValue("page") {
+		case "":
+			testFormValuesList(t, r, valuesList{
+				{"branch", "main"},
+				{"state[]", "running"},
+				{"state[]", "scheduled"},
+				{"per_page", "100"},
+			})
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"a","number":1},{"id":"b","number":2}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"c","number":3},{"id":"d"},{"id":"e"}]`)
+		default:
+			t.Errorf("unexpected page %q", r.FormValue("page"))
+		}
+	})
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		switch r.URL.Path {
+		case "/v2/organizations/my-great-org/pipelines/sup-keith/builds/1/cancel":
+			fmt.Fprint(w, `{"id":"a","number":1,"state":"canceling"}`)
+		case "/v2/organizations/my-great-org/pipelines/sup-keith/builds/3/cancel":
+			fmt.Fprint(w, `{"id":"c","number":3,"state":"canceling"}`)
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Build can't be canceled because it's already finished"}`)
+		}
+	})
+
+	builds, _, err := client.Builds.CancelByBranch("my-great-org", "sup-keith", "main")
+
+	buildErrs, ok := err.(BuildErrors)
+	if !ok {
+		t.Fatalf("Builds.CancelByBranch returned error %v, want BuildErrors", err)
+	}
+	var failed []string
+	for _, e := range buildErrs {
+		failed = append(failed, fmt.Sprintf("%d:%s", e.Index, e.ID))
+	}
+	if want := []string{"1:b", "3:d", "4:e"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("Builds.CancelByBranch returned errors for builds %v, want %v", failed, want)
+	}
+
+	want := []*Build{
+		{ID: String("a"), Number: Int(1), State: String("canceling")},
+		{ID: String("c"), Number: Int(3), State: String("canceling")},
+	}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.CancelByBranch returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_Rebuild(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1/rebuild", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		fmt.Fprint(w, `{
+  "id": "2",
+  "number": 2,
+  "state": "scheduled",
+  "rebuilt_from": {"id": "1", "number": 1}
+}`)
+	})
+
+	build, resp, err := client.Builds.Rebuild("my-great-org", "sup-keith", "1")
+	if err != nil {
+		t.Errorf("Rebuild returned error: %v", err)
+	}
+
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Rebuild returned response %+v, want status %d", resp, http.StatusOK)
+	}
+
+	want := &Build{
+		ID:          String("2"),
+		Number:      Int(2),
+		State:       String("scheduled"),
+		RebuiltFrom: &RebuiltFrom{ID: String("1"), Number: Int(1)},
+	}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Rebuild returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_Unblock(t *testing.T) {
+	setup()
+	defer teardown()
+
+	unblocked := false
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if unblocked {
+			fmt.Fprint(w, `{"id":"1","state":"running","jobs":[{"id":"a","type":"script","state":"passed"},{"id":"b","type":"manual","state":"unblocked"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1","state":"blocked","jobs":[{"id":"a","type":"script","state":"passed"},null,{"id":"b","type":"manual","state":"blocked"}]}`)
+	})
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1/jobs/b/unblock", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var v map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil || v == nil {
+			t.Errorf("Request body is not a JSON object: %v, %v", v, err)
+		}
+		unblocked = true
+		fmt.Fprint(w, `{"id":"b","type":"manual","state":"unblocked"}`)
+	})
+
+	build, _, err := client.Builds.Unblock("my-great-org", "sup-keith", "1")
+	if err != nil {
+		t.Fatalf("Unblock returned error: %v", err)
+	}
+
+	if !unblocked {
+		t.Error("Unblock did not unblock the blocked job")
+	}
+	if got, want := *build.State, "running"; got != want {
+		t.Errorf("Unblock returned build in state %v, want %v", got, want)
+	}
+}
+
+func TestBuildsService_Unblock_multiple(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","state":"blocked","jobs":[{"id":"a","type":"manual","state":"blocked"},{"id":"b","type":"manual","state":"blocked"}]}`)
+	})
+
+	if _, _, err := client.Builds.Unblock("my-great-org", "sup-keith", "1"); err == nil {
+		t.Error("Unblock returned no error for a build with two blocked jobs")
+	}
+}
+
+func TestBuildsService_Unblock_noID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","state":"blocked","jobs":[{"type":"manual","state":"blocked"}]}`)
+	})
+
+	if _, _, err := client.Builds.Unblock("my-great-org", "sup-keith", "1"); err == nil {
+		t.Error("Unblock returned no error for a blocked job without an ID")
+	}
+}
+
+func TestBuildsService_UnblockWithContext_cancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := client.Builds.UnblockWithContext(ctx, "my-great-org", "sup-keith", "1"); err == nil {
+		t.Error("UnblockWithContext returned no error for a cancelled context")
+	}
+}
+
+func TestBuildsService_WaitForBuild(t *testing.T) {
+	setup()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		calls++
+		if calls < 3 {
+			fmt.Fprint(w, `{"id":"1","state":"running"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1","state":"passed"}`)
+	})
+
+	build, err := client.Builds.WaitForBuild(context.Background(), "my-great-org", "sup-keith", "1", time.Millisecond)
+	if err != nil {
+		t.Errorf("WaitForBuild returned error: %v", err)
+	}
+
+	want := &Build{ID: String("1"), State: String("passed")}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("WaitForBuild returned %+v, want %+v", build, want)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("WaitForBuild fetched the build %d times, want %d", got, want)
+	}
+}
+
+func TestBuildsService_CreateAndWaitForJob(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func(d time.Duration) { jobPollInterval = d }(jobPollInterval)
+	jobPollInterval = time.Millisecond
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"id":"1","number":7,"state":"scheduled","jobs":[{"id":"a","step_key":"deploy","state":"waiting"}]}`)
+	})
+
+	calls := 0
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/7", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		calls++
+		if calls < 2 {
+			fmt.Fprint(w, `{"id":"1","number":7,"state":"running","jobs":[{"id":"t","step_key":"test","state":"running"},{"id":"a","step_key":"deploy","state":"waiting"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1","number":7,"state":"running","jobs":[{"id":"t","step_key":"test","state":"passed"},{"id":"a","step_key":"deploy","state":"running"}]}`)
+	})
+
+	build, job, err := client.Builds.CreateAndWaitForJob(context.Background(), "my-great-org", "sup-keith", &CreateBuild{Branch: "main"}, "deploy")
+	if err != nil {
+		t.Fatalf("CreateAndWaitForJob returned error: %v", err)
+	}
+
+	want := &Job{ID: String("a"), StepKey: String("deploy"), State: String("running")}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("CreateAndWaitForJob returned job %+v, want %+v", job, want)
+	}
+	if got, want := *build.State, "running"; got != want {
+		t.Errorf("CreateAndWaitForJob returned build in state %q, want %q", got, want)
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("CreateAndWaitForJob fetched the build %d times, want %d", got, want)
+	}
+}
+
+func TestBuildsService_CreateAndWaitForJob_finished(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","number":7,"state":"failed","jobs":[{"id":"a","step_key":"deploy","state":"waiting_failed"}]}`)
+	})
+
+	build, job, err := client.Builds.CreateAndWaitForJob(context.Background(), "my-great-org", "sup-keith", &CreateBuild{Branch: "main"}, "missing")
+	if err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+	if build == nil || job != nil {
+		t.Errorf("CreateAndWaitForJob returned build %+v and job %+v, want the build only", build, job)
+	}
+}
+
+func TestBuildsService_CreateAndWaitForJob_notRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","number":7,"state":"running","jobs":[{"id":"a","step_key":"deploy","state":"skipped"}]}`)
+	})
+
+	build, job, err := client.Builds.CreateAndWaitForJob(context.Background(), "my-great-org", "sup-keith", &CreateBuild{Branch: "main"}, "deploy")
+	if err == nil {
+		t.Fatal("CreateAndWaitForJob returned no error for a skipped job")
+	}
+	if build == nil || job == nil || *job.ID != "a" {
+		t.Errorf("CreateAndWaitForJob returned build %+v and job %+v, want the build and skipped job", build, job)
+	}
+}
+
+func TestJobHasBeenScheduled(t *testing.T) {
+	tests := []struct {
+		job     *Job
+		started bool
+		wantErr bool
+	}{
+		{&Job{}, false, false},
+		{&Job{State: String("waiting")}, false, false},
+		{&Job{State: String("limited")}, false, false},
+		{&Job{State: String("scheduled")}, true, false},
+		{&Job{State: String("running")}, true, false},
+		{&Job{State: String("passed")}, true, false},
+		{&Job{State: String("timed_out")}, true, false},
+		{&Job{State: String("canceled"), StartedAt: NewTimestamp(referenceTime)}, true, false},
+		{&Job{State: String("canceled")}, false, true},
+		{&Job{State: String("skipped")}, false, true},
+		{&Job{State: String("broken")}, false, true},
+		{&Job{State: String("expired")}, false, true},
+		{&Job{State: String("waiting_failed")}, false, true},
+	}
+
+	for _, tt := range tests {
+		started, err := jobHasBeenScheduled(tt.job)
+		if started != tt.started || (err != nil) != tt.wantErr {
+			t.Errorf("jobHasBeenScheduled(%v) returned %v, %v, want %v and error %v", tt.job, started, err, tt.started, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildsService_WaitForBuild_invalidInterval(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request for the build")
+	})
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := client.Builds.WaitForBuild(context.Background(), "my-great-org", "sup-keith", "1", interval); err == nil {
+			t.Errorf("WaitForBuild with interval %v returned no error", interval)
+		}
+	}
+}
+
+func TestBuildsService_WaitForBuild_timeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","state":"running"}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	build, err := client.Builds.WaitForBuild(ctx, "my-great-org", "sup-keith", "1", 5*time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Errorf("WaitForBuild returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if build == nil || *build.State != "running" {
+		t.Errorf("WaitForBuild returned %+v, want the last running build", build)
+	}
+}
+
+func TestBuildsService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreateBuild{Commit: "abc123", Branch: "main", Message: "Ship it"}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreateBuild)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"id":"123","number":1}`)
+	})
+
+	build, _, err := client.Builds.Create("my-great-org", "sup-keith", input)
+	if err != nil {
+		t.Errorf("Builds.Create returned error: %v", err)
+	}
+
+	want := &Build{ID: String("123"), Number: Int(1)}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.Create returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_CreateWithOptions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if got, want := r.Header.Get("Idempotency-Key"), "deploy-42"; got != want {
+			t.Errorf("Idempotency-Key header is %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	opt := &BuildCreateOptions{IdempotencyKey: "deploy-42"}
+	build, _, err := client.Builds.CreateWithOptions(context.Background(), "my-great-org", "sup-keith", &CreateBuild{Branch: "main"}, opt)
+	if err != nil {
+		t.Errorf("Builds.CreateWithOptions returned error: %v", err)
+	}
+
+	want := &Build{ID: String("123")}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.CreateWithOptions returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_Create_head(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if got, want := v["commit"], "HEAD"; got != want {
+			t.Errorf("Request body commit = %v, want %v", got, want)
+		}
+
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	input := &CreateBuild{Branch: "main"}
+	_, _, err := client.Builds.Create("my-great-org", "sup-keith", input)
+	if err != nil {
+		t.Errorf("Builds.Create returned error: %v", err)
+	}
+
+	if input.Commit != "" {
+		t.Errorf("Builds.Create modified the input commit to %q", input.Commit)
+	}
+}
+
+func TestBuildsService_Create_invalid(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Builds.Create sent a request for an invalid build")
+	})
+
+	tests := []struct {
+		build *CreateBuild
+		want  string
+	}{
+		{nil, "buildkite: CreateBuild must not be nil"},
+		{&CreateBuild{}, "buildkite: CreateBuild requires Branch"},
+		{&CreateBuild{Commit: "abc123"}, "buildkite: CreateBuild requires Branch"},
+		{&CreateBuild{Branch: "feature", PullRequestID: 42}, "buildkite: CreateBuild with PullRequestID requires PullRequestRepository"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := client.Builds.Create("my-great-org", "sup-keith", tt.build)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Builds.Create(%+v) returned error %v, want %q", tt.build, err, tt.want)
+		}
+	}
+}
+
 func TestBuildsService_List(t *testing.T) {
 	setup()
 	defer teardown()
@@ -56,100 +498,599 @@ func TestBuildsService_Get(t *testing.T) {
 	setup()
 	defer teardown()
 
-	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/123", func(w http.ResponseWriter, r *http.Request) {
-		testMethod(t, r, "GET")
-		fmt.Fprint(w, `{"id":"123"}`)
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123"}`)
+	})
+
+	build, _, err := client.Builds.Get("my-great-org", "sup-keith", "123")
+	if err != nil {
+		t.Errorf("Builds.Get returned error: %v", err)
+	}
+
+	want := &Build{ID: String("123")}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.Get returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_GetWithOptions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"include_retried_jobs": "true"})
+		fmt.Fprint(w, `{"id":"1","jobs":[{"id":"a","retried":true},{"id":"b"}]}`)
+	})
+
+	build, _, err := client.Builds.GetWithOptions(context.Background(), "my-great-org", "sup-keith", "1", &BuildGetOptions{IncludeRetriedJobs: true})
+	if err != nil {
+		t.Errorf("Builds.GetWithOptions returned error: %v", err)
+	}
+
+	want := &Build{ID: String("1"), Jobs: []*Job{{ID: String("a"), Retried: Bool(true)}, {ID: String("b")}}}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.GetWithOptions returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_GetByNumber(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/42", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123","number":42}`)
+	})
+
+	build, _, err := client.Builds.GetByNumber("my-great-org", "sup-keith", 42)
+	if err != nil {
+		t.Errorf("Builds.GetByNumber returned error: %v", err)
+	}
+
+	want := &Build{ID: String("123"), Number: Int(42)}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.GetByNumber returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_GetMany(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.URL.Path {
+		case "/v2/organizations/my-great-org/pipelines/sup-keith/builds/1":
+			fmt.Fprint(w, `{"id":"a","number":1}`)
+		case "/v2/organizations/my-great-org/pipelines/sup-keith/builds/3":
+			fmt.Fprint(w, `{"id":"c","number":3}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	builds, err := client.Builds.GetMany(context.Background(), "my-great-org", "sup-keith", []int{3, 2, 1})
+
+	buildErrs, ok := err.(BuildErrors)
+	if !ok {
+		t.Fatalf("Builds.GetMany returned error %v, want BuildErrors", err)
+	}
+	if len(buildErrs) != 1 || buildErrs[0].Index != 1 || buildErrs[0].Number != 2 {
+		t.Errorf("Builds.GetMany returned errors %v, want one for build 2", buildErrs)
+	}
+
+	want := []*Build{{ID: String("c"), Number: Int(3)}, nil, {ID: String("a"), Number: Int(1)}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.GetMany returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_GetMany_repeated(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.URL.Path {
+		case "/v2/organizations/my-great-org/pipelines/sup-keith/builds/1":
+			fmt.Fprint(w, `{"id":"a","number":1}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	builds, err := client.Builds.GetMany(context.Background(), "my-great-org", "sup-keith", []int{1, 2, 1, 2})
+
+	buildErrs, ok := err.(BuildErrors)
+	if !ok {
+		t.Fatalf("Builds.GetMany returned error %v, want BuildErrors", err)
+	}
+	var failed []int
+	for _, e := range buildErrs {
+		failed = append(failed, e.Index)
+	}
+	if want := []int{1, 3}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("Builds.GetMany returned errors at indexes %v, want %v", failed, want)
+	}
+
+	a := &Build{ID: String("a"), Number: Int(1)}
+	want := []*Build{a, nil, a, nil}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.GetMany returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_GetMany_cancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %v", r.URL.Path)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.Builds.GetMany(ctx, "my-great-org", "sup-keith", []int{1, 2})
+
+	buildErrs, ok := err.(BuildErrors)
+	if !ok || len(buildErrs) != 2 {
+		t.Fatalf("Builds.GetMany returned error %v, want an error for each build", err)
+	}
+	for _, e := range buildErrs {
+		if e.Err != context.Canceled {
+			t.Errorf("Builds.GetMany returned error %v for build %d, want %v", e.Err, e.Number, context.Canceled)
+		}
+	}
+}
+
+func TestBuildsService_Get_meta_data(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{
+  "id": "123",
+  "meta_data": {
+    "release-version": "1.2.3",
+    "deploy-target": "production"
+  }
+}`)
+	})
+
+	build, _, err := client.Builds.Get("my-great-org", "sup-keith", "123")
+	if err != nil {
+		t.Errorf("Builds.Get returned error: %v", err)
+	}
+
+	want := &Build{
+		ID:       String("123"),
+		MetaData: map[string]string{"release-version": "1.2.3", "deploy-target": "production"},
+	}
+	if !reflect.DeepEqual(build, want) {
+		t.Errorf("Builds.Get returned %+v, want %+v", build, want)
+	}
+}
+
+func TestBuildsService_GetEnv(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/123/env", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"env":{"BUILDKITE":"true","DEPLOY_TARGET":"staging"}}`)
+	})
+
+	env, _, err := client.Builds.GetEnv("my-great-org", "sup-keith", "123")
+	if err != nil {
+		t.Errorf("Builds.GetEnv returned error: %v", err)
+	}
+
+	want := map[string]string{"BUILDKITE": "true", "DEPLOY_TARGET": "staging"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("Builds.GetEnv returned %+v, want %+v", env, want)
+	}
+}
+
+func TestBuildsService_ListAnnotations(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1/annotations", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"page": "2"})
+		fmt.Fprint(w, `[{"id":"68aef727","context":"coverage"}]`)
+	})
+
+	annotations, _, err := client.Builds.ListAnnotations("my-great-org", "sup-keith", "1", &ListOptions{Page: 2})
+	if err != nil {
+		t.Errorf("Builds.ListAnnotations returned error: %v", err)
+	}
+
+	want := []Annotation{{ID: String("68aef727"), Context: String("coverage")}}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("Builds.ListAnnotations returned %+v, want %+v", annotations, want)
+	}
+}
+
+func TestBuildsService_GetMetaData(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"1","meta_data":{"digest":"sha256:abc","release":"rc-1"}}`)
+	})
+
+	metaData, _, err := client.Builds.ListMetaData("my-great-org", "sup-keith", "1")
+	if err != nil {
+		t.Errorf("Builds.ListMetaData returned error: %v", err)
+	}
+	if want := map[string]string{"digest": "sha256:abc", "release": "rc-1"}; !reflect.DeepEqual(metaData, want) {
+		t.Errorf("Builds.ListMetaData returned %+v, want %+v", metaData, want)
+	}
+
+	value, _, err := client.Builds.GetMetaData("my-great-org", "sup-keith", "1", "digest")
+	if err != nil {
+		t.Errorf("Builds.GetMetaData returned error: %v", err)
+	}
+	if want := "sha256:abc"; value != want {
+		t.Errorf("Builds.GetMetaData returned %q, want %q", value, want)
+	}
+
+	if _, _, err := client.Builds.GetMetaData("my-great-org", "sup-keith", "1", "missing"); err == nil {
+		t.Error("Builds.GetMetaData returned no error for a missing key")
+	}
+}
+
+func TestBuildsService_SetMetaData(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.AgentBaseURL = client.BaseURL
+
+	mux.HandleFunc("/v3/jobs/b63254c0/data/set", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if got, want := r.Header.Get("Authorization"), "Token my-access-token"; got != want {
+			t.Errorf("Authorization header is %q, want %q", got, want)
+		}
+
+		var v map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&v)
+		want := map[string]interface{}{"key": "digest", "value": "sha256:abc"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if _, err := client.Builds.SetMetaData("b63254c0", "my-access-token", "digest", "sha256:abc"); err != nil {
+		t.Errorf("Builds.SetMetaData returned error: %v", err)
+	}
+
+	if _, err := client.Builds.SetMetaData("b63254c0", "", "digest", "sha256:abc"); err == nil {
+		t.Error("Builds.SetMetaData returned no error for an empty access token")
+	}
+	if _, err := client.Builds.SetMetaData("b63254c0", "my-access-token", "", "sha256:abc"); err == nil {
+		t.Error("Builds.SetMetaData returned no error for an empty key")
+	}
+}
+
+func TestBuildsService_List_by_status(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"state[]": "running",
+			"page":    "2",
+		})
+		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+	})
+
+	opt := &BuildsListOptions{
+		State:       []string{"running"},
+		ListOptions: ListOptions{Page: 2},
+	}
+	builds, _, err := client.Builds.List(opt)
+	if err != nil {
+		t.Errorf("Builds.List returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("123")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_List_by_multiple_status(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValuesList(t, r, valuesList{
+			{"state[]", "running"},
+			{"state[]", "scheduled"},
+			{"page", "2"},
+		})
+		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+	})
+
+	opt := &BuildsListOptions{
+		State:       []string{"running", "scheduled"},
+		ListOptions: ListOptions{Page: 2},
+	}
+	builds, _, err := client.Builds.List(opt)
+	if err != nil {
+		t.Errorf("Builds.List returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("123")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_List_by_BuildState(t *testing.T) {
+	setup()
+	defer teardown()
+
+	states := map[BuildState]string{
+		BuildStateScheduled: "scheduled",
+		BuildStateRunning:   "running",
+		BuildStateFailing:   "failing",
+		BuildStateCanceling: "canceling",
+		BuildStatePassed:    "passed",
+		BuildStateFailed:    "failed",
+		BuildStateCanceled:  "canceled",
+		BuildStateSkipped:   "skipped",
+		BuildStateNotRun:    "not_run",
+		BuildStateBlocked:   "blocked",
+	}
+	for state, want := range states {
+		if string(state) != want {
+			t.Errorf("BuildState is %q, want %q", state, want)
+		}
+	}
+
+	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValuesList(t, r, valuesList{
+			{"state[]", "passed"},
+			{"state[]", "not_run"},
+		})
+		fmt.Fprint(w, `[{"id":"123","state":"passed"}]`)
+	})
+
+	opt := &BuildsListOptions{
+		State: []string{string(BuildStatePassed), string(BuildStateNotRun)},
+	}
+	builds, _, err := client.Builds.List(opt)
+	if err != nil {
+		t.Errorf("Builds.List returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("123"), State: String("passed")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
+	}
+	if len(builds) == 1 && BuildState(*builds[0].State) != BuildStatePassed {
+		t.Errorf("Builds.List returned state %q, want %q", *builds[0].State, BuildStatePassed)
+	}
+}
+
+func TestBuildsService_List_by_multiple_branches(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValuesList(t, r, valuesList{
+			{"branch[]", "main"},
+			{"branch[]", "release/1.0"},
+		})
+		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+	})
+
+	opt := &BuildsListOptions{Branches: []string{"main", "release/1.0"}}
+	builds, _, err := client.Builds.List(opt)
+	if err != nil {
+		t.Errorf("Builds.List returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("123")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_ListAllByPipeline_branchGlob(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got := r.FormValue("branch"); got != "" {
+			t.Errorf("branch sent as %q for a glob", got)
+		}
+		switch r.FormValue("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"1","branch":"release/1.0"},{"id":"2","branch":"main"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"3","branch":"release/1.1"},{"id":"4","branch":"release/1.1/hotfix"}]`)
+		}
+	})
+
+	builds, err := client.Builds.ListAllByPipeline("my-great-org", "sup-keith", &BuildsListOptions{BranchGlob: "release/*"})
+	if err != nil {
+		t.Fatalf("Builds.ListAllByPipeline returned error: %v", err)
+	}
+
+	want := []Build{
+		{ID: String("1"), Branch: String("release/1.0")},
+		{ID: String("3"), Branch: String("release/1.1")},
+	}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.ListAllByPipeline returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_ListAllByPipeline_branchGlobLaterPage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.FormValue("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"1","branch":"main"},{"id":"2","branch":"main"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"3","branch":"main"},{"id":"4","branch":"release/1.1"}]`)
+		}
+	})
+
+	opt := &BuildsListOptions{BranchGlob: "release/*"}
+	want := []Build{{ID: String("4"), Branch: String("release/1.1")}}
+
+	builds, err := client.Builds.ListAllByPipeline("my-great-org", "sup-keith", opt)
+	if err != nil {
+		t.Fatalf("Builds.ListAllByPipeline returned error: %v", err)
+	}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.ListAllByPipeline returned %+v, want %+v", builds, want)
+	}
+
+	var iterated []Build
+	it := client.Builds.IterateByPipeline("my-great-org", "sup-keith", opt)
+	for {
+		build, err := it.Next()
+		if err != nil {
+			t.Fatalf("BuildIterator.Next returned error: %v", err)
+		}
+		if build == nil {
+			break
+		}
+		iterated = append(iterated, *build)
+	}
+	if !reflect.DeepEqual(iterated, want) {
+		t.Errorf("BuildIterator returned %+v, want %+v", iterated, want)
+	}
+
+	if _, _, err := client.Builds.ListByPipeline("my-great-org", "sup-keith", opt); err == nil {
+		t.Error("Builds.ListByPipeline returned no error for a BranchGlob with wildcards")
+	}
+}
+
+func TestBuildsService_List_branchGlobExact(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
+		testFormValues(t, r, values{"branch": "main"})
+		fmt.Fprint(w, `[{"id":"1","branch":"main"}]`)
 	})
 
-	build, _, err := client.Builds.Get("my-great-org", "sup-keith", "123")
-	if err != nil {
-		t.Errorf("Builds.Get returned error: %v", err)
+	if _, _, err := client.Builds.List(&BuildsListOptions{BranchGlob: "main"}); err != nil {
+		t.Errorf("Builds.List returned error: %v", err)
 	}
 
-	want := &Build{ID: String("123")}
-	if !reflect.DeepEqual(build, want) {
-		t.Errorf("Builds.Get returned %+v, want %+v", build, want)
+	if _, _, err := client.Builds.List(&BuildsListOptions{BranchGlob: "release/["}); err == nil {
+		t.Error("Builds.List returned no error for an invalid BranchGlob")
 	}
 }
 
-func TestBuildsService_List_by_status(t *testing.T) {
+func TestBuildsService_List_by_created_date(t *testing.T) {
 	setup()
 	defer teardown()
 
+	ts, err := time.Parse(BuildKiteDateFormat, "2016-03-24T01:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
 		testFormValues(t, r, values{
-			"state[]": "running",
-			"page":    "2",
+			"created_from": "2016-03-24T01:00:00Z",
+			"created_to":   "2016-03-24T02:00:00Z",
 		})
-		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+		fmt.Fprint(w, `[{"id":"123"}]`)
 	})
 
 	opt := &BuildsListOptions{
-		State:       []string{"running"},
-		ListOptions: ListOptions{Page: 2},
+		CreatedFrom: ts,
+		CreatedTo:   ts.Add(time.Hour),
 	}
 	builds, _, err := client.Builds.List(opt)
 	if err != nil {
 		t.Errorf("Builds.List returned error: %v", err)
 	}
 
-	want := []Build{{ID: String("123")}, {ID: String("1234")}}
+	want := []Build{{ID: String("123")}}
 	if !reflect.DeepEqual(builds, want) {
 		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
 	}
 }
 
-func TestBuildsService_List_by_multiple_status(t *testing.T) {
+func TestBuildsService_List_by_finished_date(t *testing.T) {
 	setup()
 	defer teardown()
 
+	ts, err := time.Parse(BuildKiteDateFormat, "2016-03-24T01:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
-		testFormValuesList(t, r, valuesList{
-			{"state[]", "running"},
-			{"state[]", "scheduled"},
-			{"page", "2"},
+		testFormValues(t, r, values{
+			"finished_from": "2016-03-24T01:00:00Z",
+			"finished_to":   "2016-03-24T02:00:00Z",
 		})
-		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+		fmt.Fprint(w, `[{"id":"123"}]`)
 	})
 
 	opt := &BuildsListOptions{
-		State:       []string{"running", "scheduled"},
-		ListOptions: ListOptions{Page: 2},
+		FinishedFrom: ts,
+		FinishedTo:   ts.Add(time.Hour),
 	}
 	builds, _, err := client.Builds.List(opt)
 	if err != nil {
 		t.Errorf("Builds.List returned error: %v", err)
 	}
 
-	want := []Build{{ID: String("123")}, {ID: String("1234")}}
+	want := []Build{{ID: String("123")}}
 	if !reflect.DeepEqual(builds, want) {
 		t.Errorf("Builds.List returned %+v, want %+v", builds, want)
 	}
 }
 
-func TestBuildsService_List_by_created_date(t *testing.T) {
+func TestBuildsService_List_by_meta_data(t *testing.T) {
 	setup()
 	defer teardown()
 
-	ts, err := time.Parse(BuildKiteDateFormat, "2016-03-24T01:00:00Z")
-	if err != nil {
-		t.Fatal(err)
-	}
-
 	mux.HandleFunc("/v2/builds", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
+		if got, want := r.URL.RawQuery, "meta_data%5Brelease+candidate%5D=rc-1%262&meta_data%5Bteam%5D=infra"; got != want {
+			t.Errorf("Query is %v, want %v", got, want)
+		}
 		testFormValues(t, r, values{
-			"created_from": "2016-03-24T01:00:00Z",
-			"created_to":   "2016-03-24T02:00:00Z",
+			"meta_data[release candidate]": "rc-1&2",
+			"meta_data[team]":              "infra",
 		})
 		fmt.Fprint(w, `[{"id":"123"}]`)
 	})
 
 	opt := &BuildsListOptions{
-		CreatedFrom: ts,
-		CreatedTo:   ts.Add(time.Hour),
+		MetaData: MetaDataFilters{"release candidate": "rc-1&2", "team": "infra"},
 	}
 	builds, _, err := client.Builds.List(opt)
 	if err != nil {
@@ -211,6 +1152,44 @@ func TestBuildsService_ListByOrg_branch_commit(t *testing.T) {
 	}
 }
 
+func TestBuildsService_ListRunning(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValuesList(t, r, valuesList{
+			{"branch", "main"},
+			{"state[]", "running"},
+			{"state[]", "scheduled"},
+		})
+		fmt.Fprint(w, `[{"id":"123"}]`)
+	})
+
+	opt := &BuildsListOptions{Branch: "main", State: []string{"passed"}}
+	builds, _, err := client.Builds.ListRunning("my-great-org", opt)
+	if err != nil {
+		t.Errorf("Builds.ListRunning returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("123")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.ListRunning returned %+v, want %+v", builds, want)
+	}
+	if got, want := opt.State, []string{"passed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Builds.ListRunning modified opt.State to %v, want %v", got, want)
+	}
+}
+
+func TestBuildURLs(t *testing.T) {
+	if got, want := BuildWebURL("my-great-org", "sup-keith", 42), "https://buildkite.com/my-great-org/sup-keith/builds/42"; got != want {
+		t.Errorf("BuildWebURL returned %v, want %v", got, want)
+	}
+	if got, want := BuildAPIURL("my-great-org", "sup-keith", 42), "https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds/42"; got != want {
+		t.Errorf("BuildAPIURL returned %v, want %v", got, want)
+	}
+}
+
 func TestBuildsService_ListByPipeline(t *testing.T) {
 	setup()
 	defer teardown()
@@ -231,6 +1210,441 @@ func TestBuildsService_ListByPipeline(t *testing.T) {
 	}
 }
 
+func TestBuildsService_ListByPipeline_includeRetriedJobs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var want values
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, want)
+		fmt.Fprint(w, `[]`)
+	})
+
+	want = values{"include_retried_jobs": "true"}
+	if _, _, err := client.Builds.ListByPipeline("my-great-org", "sup-keith", &BuildsListOptions{IncludeRetriedJobs: true}); err != nil {
+		t.Errorf("Builds.ListByPipeline returned error: %v", err)
+	}
+
+	want = values{}
+	if _, _, err := client.Builds.ListByPipeline("my-great-org", "sup-keith", &BuildsListOptions{IncludeRetriedJobs: false}); err != nil {
+		t.Errorf("Builds.ListByPipeline returned error: %v", err)
+	}
+}
+
+func TestBuildsService_withContext_cancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := client.Builds.CancelByBranchWithContext(ctx, "my-great-org", "sup-keith", "main"); err == nil {
+		t.Error("Builds.CancelByBranchWithContext returned no error for a cancelled context")
+	}
+	if _, err := client.Builds.ListAllByPipelineWithContext(ctx, "my-great-org", "sup-keith", nil); err == nil {
+		t.Error("Builds.ListAllByPipelineWithContext returned no error for a cancelled context")
+	}
+	if _, err := client.Builds.ListByPipelinesWithContext(ctx, "my-great-org", []string{"a", "b"}, nil); err == nil {
+		t.Error("Builds.ListByPipelinesWithContext returned no error for a cancelled context")
+	}
+	if _, err := client.Builds.IterateByPipelineWithContext(ctx, "my-great-org", "sup-keith", nil).Next(); err == nil {
+		t.Error("BuildIterator.Next returned no error for a cancelled context")
+	}
+	if requests != 0 {
+		t.Errorf("Server received %d requests after the context was cancelled, want 0", requests)
+	}
+}
+
+func TestBuildsService_ListByPipelines(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"branch": "main"})
+		switch r.URL.Path {
+		case "/v2/organizations/my-great-org/pipelines/web/builds":
+			fmt.Fprint(w, `[{"id":"w2","created_at":"2015-03-04T02:30:00Z"},{"id":"w1","created_at":"2015-03-04T02:20:00Z"}]`)
+		case "/v2/organizations/my-great-org/pipelines/api/builds":
+			fmt.Fprint(w, `[{"id":"a1","created_at":"2015-03-04T02:25:00Z"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	builds, err := client.Builds.ListByPipelines("my-great-org", []string{"web", "api"}, &BuildsListOptions{Branch: "main"})
+	if err != nil {
+		t.Fatalf("Builds.ListByPipelines returned error: %v", err)
+	}
+
+	var ids []string
+	for _, b := range builds {
+		ids = append(ids, *b.ID)
+	}
+	if want := []string{"w2", "a1", "w1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Builds.ListByPipelines returned builds %v, want %v", ids, want)
+	}
+
+	if _, err := client.Builds.ListByPipelines("my-great-org", []string{"web", "missing"}, &BuildsListOptions{Branch: "main"}); err == nil {
+		t.Error("Builds.ListByPipelines returned no error for a missing pipeline")
+	}
+}
+
+func TestBuildsService_ListAllByPipeline(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		r.ParseForm()
+		if got, want := r.Form.Get("per_page"), "2"; got != want {
+			t.Errorf("per_page is %v, want %v", got, want)
+		}
+		switch r.Form.Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2&per_page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"1"},{"id":"2"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"3"}]`)
+		default:
+			t.Errorf("unexpected page %q", r.Form.Get("page"))
+		}
+	})
+
+	opt := &BuildsListOptions{ListOptions: ListOptions{PerPage: 2}}
+	builds, err := client.Builds.ListAllByPipeline("my-great-org", "sup-keith", opt)
+	if err != nil {
+		t.Errorf("Builds.ListAllByPipeline returned error: %v", err)
+	}
+
+	want := []Build{{ID: String("1")}, {ID: String("2")}, {ID: String("3")}}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.ListAllByPipeline returned %+v, want %+v", builds, want)
+	}
+
+	if opt.Page != 0 {
+		t.Errorf("Builds.ListAllByPipeline modified the options page to %d", opt.Page)
+	}
+}
+
+func TestBuildsService_ListByPullRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.FormValue("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"1","pull_request":{"id":"42"}},{"id":"2"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"3","pull_request":{"id":"7"}},{"id":"4","pull_request":{"id":"42"}}]`)
+		default:
+			t.Errorf("unexpected page %q", r.FormValue("page"))
+		}
+	})
+
+	builds, err := client.Builds.ListByPullRequest("my-great-org", "sup-keith", 42, nil)
+	if err != nil {
+		t.Errorf("Builds.ListByPullRequest returned error: %v", err)
+	}
+
+	want := []Build{
+		{ID: String("1"), PullRequest: &PullRequest{ID: String("42")}},
+		{ID: String("4"), PullRequest: &PullRequest{ID: String("42")}},
+	}
+	if !reflect.DeepEqual(builds, want) {
+		t.Errorf("Builds.ListByPullRequest returned %+v, want %+v", builds, want)
+	}
+}
+
+func TestBuildsService_IterateByPipeline(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.FormValue("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"id":"1"},{"id":"2"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":"3"}]`)
+		default:
+			t.Errorf("unexpected page %q", r.FormValue("page"))
+		}
+	})
+
+	it := client.Builds.IterateByPipeline("my-great-org", "sup-keith", nil)
+
+	var ids []string
+	for {
+		build, err := it.Next()
+		if err != nil {
+			t.Fatalf("BuildIterator.Next returned error: %v", err)
+		}
+		if build == nil {
+			break
+		}
+		ids = append(ids, *build.ID)
+	}
+
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("BuildIterator returned builds %v, want %v", ids, want)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("BuildIterator.Err returned %v", err)
+	}
+}
+
+func TestBuildsService_IterateByPipeline_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	it := client.Builds.IterateByPipeline("my-great-org", "sup-keith", nil)
+
+	build, err := it.Next()
+	if err == nil || build != nil {
+		t.Fatalf("BuildIterator.Next returned %v, %v, want an error", build, err)
+	}
+	if it.Err() != err {
+		t.Errorf("BuildIterator.Err returned %v, want %v", it.Err(), err)
+	}
+	if _, again := it.Next(); again != err {
+		t.Errorf("BuildIterator.Next after an error returned %v, want %v", again, err)
+	}
+}
+
+func TestBuild_IsFinished(t *testing.T) {
+	var tests = []struct {
+		state    *string
+		finished bool
+		running  bool
+	}{
+		{nil, false, false},
+		{String("scheduled"), false, false},
+		{String("running"), false, true},
+		{String("failing"), false, true},
+		{String("canceling"), false, true},
+		{String("passed"), true, false},
+		{String("failed"), true, false},
+		{String("canceled"), true, false},
+		{String("skipped"), true, false},
+		{String("not_run"), true, false},
+		{String("blocked"), true, false},
+	}
+
+	for _, tt := range tests {
+		b := &Build{State: tt.state}
+		if got := b.IsFinished(); got != tt.finished {
+			t.Errorf("IsFinished() for state %v is %v, want %v", Stringify(tt.state), got, tt.finished)
+		}
+		if got := b.IsRunning(); got != tt.running {
+			t.Errorf("IsRunning() for state %v is %v, want %v", Stringify(tt.state), got, tt.running)
+		}
+	}
+}
+
+func TestBuild_Duration(t *testing.T) {
+	scheduled := NewTimestamp(referenceTime)
+	started := NewTimestamp(referenceTime.Add(30 * time.Second))
+	finished := NewTimestamp(referenceTime.Add(5 * time.Minute))
+
+	var tests = []struct {
+		build    *Build
+		duration time.Duration
+		ok       bool
+		queued   time.Duration
+		queuedOK bool
+	}{
+		{&Build{}, 0, false, 0, false},
+		{&Build{ScheduledAt: scheduled}, 0, false, 0, false},
+		{&Build{ScheduledAt: scheduled, StartedAt: started}, 0, false, 30 * time.Second, true},
+		{&Build{ScheduledAt: scheduled, StartedAt: started, FinishedAt: finished}, 4*time.Minute + 30*time.Second, true, 30 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		if d, ok := tt.build.Duration(); d != tt.duration || ok != tt.ok {
+			t.Errorf("Duration() for %v is %v, %v, want %v, %v", tt.build, d, ok, tt.duration, tt.ok)
+		}
+		if d, ok := tt.build.QueueDuration(); d != tt.queued || ok != tt.queuedOK {
+			t.Errorf("QueueDuration() for %v is %v, %v, want %v, %v", tt.build, d, ok, tt.queued, tt.queuedOK)
+		}
+	}
+}
+
+func TestBuild_FailedJobs(t *testing.T) {
+	passed := &Job{ID: String("1"), State: String("passed"), ExitStatus: Int(0)}
+	failed := &Job{ID: String("2"), State: String("failed"), ExitStatus: Int(1)}
+	timedOut := &Job{ID: String("3"), State: String("timed_out")}
+	softFailed := &Job{ID: String("4"), State: String("failed"), ExitStatus: Int(1), SoftFailed: Bool(true)}
+	noState := &Job{ID: String("5")}
+	runningSoftFail := &Job{ID: String("6"), State: String("running"), SoftFailed: Bool(true)}
+	noStateSoftFail := &Job{ID: String("7"), SoftFailed: Bool(true)}
+
+	b := &Build{Jobs: []*Job{passed, failed, nil, timedOut, softFailed, noState, runningSoftFail, noStateSoftFail}}
+
+	if got, want := b.FailedJobs(), []*Job{failed, timedOut}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FailedJobs returned %v, want %v", got, want)
+	}
+	if got, want := b.SoftFailedJobs(), []*Job{softFailed}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SoftFailedJobs returned %v, want %v", got, want)
+	}
+
+	if got := (&Build{}).FailedJobs(); got != nil {
+		t.Errorf("FailedJobs for a build without jobs returned %v, want nil", got)
+	}
+}
+
+func TestBuild_PendingQueues(t *testing.T) {
+	b := &Build{Jobs: []*Job{
+		{State: String("scheduled"), AgentQueryRules: []string{"queue=deploy", "os=linux"}},
+		{State: String("scheduled"), AgentQueryRules: []string{"os=linux"}},
+		{State: String("scheduled"), AgentQueryRules: []string{"queue=deploy"}},
+		{State: String("running"), AgentQueryRules: []string{"queue=builders"}},
+		{State: String("waiting"), AgentQueryRules: []string{"queue=release"}},
+		{},
+		nil,
+	}}
+
+	if got, want := b.PendingQueues(), []string{"default", "deploy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PendingQueues returned %v, want %v", got, want)
+	}
+
+	if got := (&Build{}).PendingQueues(); got != nil {
+		t.Errorf("PendingQueues for a build without jobs returned %v, want nil", got)
+	}
+}
+
+func TestNewPullRequestBuild(t *testing.T) {
+	got := NewPullRequestBuild("feature", "abc123", 42, "git://github.com/my-great-org/my-repo.git")
+
+	want := &CreateBuild{
+		Branch:                "feature",
+		Commit:                "abc123",
+		PullRequestID:         42,
+		PullRequestRepository: "git://github.com/my-great-org/my-repo.git",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPullRequestBuild returned %+v, want %+v", got, want)
+	}
+
+	if err := got.validate(); err != nil {
+		t.Errorf("NewPullRequestBuild returned an invalid build: %v", err)
+	}
+}
+
+func TestCreateBuild_WithCleanCheckout(t *testing.T) {
+	cb := (&CreateBuild{Env: map[string]string{"FOO": "bar"}}).WithCleanCheckout()
+
+	want := map[string]string{"FOO": "bar", "BUILDKITE_CLEAN_CHECKOUT": "true"}
+	if !reflect.DeepEqual(cb.Env, want) {
+		t.Errorf("CreateBuild.WithCleanCheckout set Env to %+v, want %+v", cb.Env, want)
+	}
+
+	cb = (&CreateBuild{}).WithCleanCheckout()
+	if got := cb.Env["BUILDKITE_CLEAN_CHECKOUT"]; got != "true" {
+		t.Errorf("CreateBuild.WithCleanCheckout on nil Env set %q, want %q", got, "true")
+	}
+}
+
+func TestCreateBuild_roundTrip(t *testing.T) {
+	cb := CreateBuild{
+		Commit:   "abc123",
+		Branch:   "main",
+		Message:  "Ship it",
+		Env:      map[string]string{"DEPLOY": "true"},
+		MetaData: map[string]string{"release": "rc-1"},
+	}
+
+	data, err := json.Marshal(cb)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	var b Build
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	want := Build{
+		Commit:   String("abc123"),
+		Branch:   String("main"),
+		Message:  String("Ship it"),
+		Env:      BuildEnv{"DEPLOY": "true"},
+		MetaData: map[string]string{"release": "rc-1"},
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Errorf("CreateBuild round tripped to %+v, want %+v", b, want)
+	}
+}
+
+func TestBuildEnv_UnmarshalJSON(t *testing.T) {
+	var b Build
+	err := json.Unmarshal([]byte(`{"env":{"NAME":"web","REPLICAS":3,"DEBUG":false,"EMPTY":null}}`), &b)
+	if err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	want := BuildEnv{"NAME": "web", "REPLICAS": "3", "DEBUG": "false", "EMPTY": ""}
+	if !reflect.DeepEqual(b.Env, want) {
+		t.Errorf("Build.Env is %+v, want %+v", b.Env, want)
+	}
+}
+
+func TestBuild_UnmarshalJSON_branch(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *string
+	}{
+		{"String", `{"id":"1","branch":"main"}`, String("main")},
+		{"List", `{"id":"1","branch":["main"]}`, String("main")},
+		{"EmptyList", `{"id":"1","branch":[]}`, nil},
+		{"Null", `{"id":"1","branch":null}`, nil},
+		{"Missing", `{"id":"1"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Build
+			if err := json.Unmarshal([]byte(tt.data), &b); err != nil {
+				t.Fatalf("json.Unmarshal returned error: %v", err)
+			}
+
+			want := Build{ID: String("1"), Branch: tt.want}
+			if !reflect.DeepEqual(b, want) {
+				t.Errorf("json.Unmarshal returned %+v, want %+v", b, want)
+			}
+		})
+	}
+
+	var b Build
+	if err := json.Unmarshal([]byte(`{"branch":42}`), &b); err == nil {
+		t.Error("json.Unmarshal returned no error for a numeric branch")
+	}
+
+	// as with encoding/json, a missing key leaves the field untouched
+	b = Build{Branch: String("main")}
+	if err := json.Unmarshal([]byte(`{"id":"1"}`), &b); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if want := (Build{ID: String("1"), Branch: String("main")}); !reflect.DeepEqual(b, want) {
+		t.Errorf("json.Unmarshal returned %+v, want %+v", b, want)
+	}
+}
+
 func TestBuildsUnmarshalWebhook(t *testing.T) {
 	// payload taken from buildkite services console
 	sampleData := `{
@@ -269,3 +1683,20 @@ func TestBuildsUnmarshalWebhook(t *testing.T) {
 		t.Fatalf("could not unmarshal: %v", err)
 	}
 }
+
+func TestBuildsService_GetWithContext_cancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/123", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not have been sent")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := client.Builds.GetWithContext(ctx, "my-great-org", "sup-keith", "123")
+	if err != context.Canceled {
+		t.Errorf("Builds.GetWithContext returned error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ClustersService handles communication with the cluster related
+// methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters
+type ClustersService struct {
+	client *Client
+}
+
+// CreateCluster - Create a cluster.
+type CreateCluster struct {
+	Name string `json:"name"`
+
+	// Optional fields
+	Description string `json:"description,omitempty"`
+	Emoji       string `json:"emoji,omitempty"`
+	Color       string `json:"color,omitempty"`
+}
+
+// UpdateCluster - Update a cluster. Fields left nil are not changed.
+type UpdateCluster struct {
+	Name           *string `json:"name,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	Emoji          *string `json:"emoji,omitempty"`
+	Color          *string `json:"color,omitempty"`
+	DefaultQueueID *string `json:"default_queue_id,omitempty"`
+}
+
+// Cluster represents a buildkite cluster, a group of agent queues.
+type Cluster struct {
+	ID              *string    `json:"id,omitempty"`
+	URL             *string    `json:"url,omitempty"`
+	WebURL          *string    `json:"web_url,omitempty"`
+	Name            *string    `json:"name,omitempty"`
+	Description     *string    `json:"description,omitempty"`
+	Emoji           *string    `json:"emoji,omitempty"`
+	Color           *string    `json:"color,omitempty"`
+	DefaultQueueID  *string    `json:"default_queue_id,omitempty"`
+	DefaultQueueURL *string    `json:"default_queue_url,omitempty"`
+	QueuesURL       *string    `json:"queues_url,omitempty"`
+	CreatedAt       *Timestamp `json:"created_at,omitempty"`
+	CreatedBy       *User      `json:"created_by,omitempty"`
+}
+
+// CreateClusterQueue - Create a cluster queue.
+type CreateClusterQueue struct {
+	Key string `json:"key"`
+
+	// Optional fields
+	Description string `json:"description,omitempty"`
+}
+
+// ClusterQueue represents an agent queue within a cluster.
+type ClusterQueue struct {
+	ID          *string    `json:"id,omitempty"`
+	URL         *string    `json:"url,omitempty"`
+	WebURL      *string    `json:"web_url,omitempty"`
+	Key         *string    `json:"key,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	ClusterID   *string    `json:"cluster_id,omitempty"`
+	ClusterURL  *string    `json:"cluster_url,omitempty"`
+	CreatedAt   *Timestamp `json:"created_at,omitempty"`
+	CreatedBy   *User      `json:"created_by,omitempty"`
+
+	DispatchPaused     bool       `json:"dispatch_paused"`
+	DispatchPausedAt   *Timestamp `json:"dispatch_paused_at,omitempty"`
+	DispatchPausedBy   *User      `json:"dispatch_paused_by,omitempty"`
+	DispatchPausedNote *string    `json:"dispatch_paused_note,omitempty"`
+}
+
+// ClusterQueuePause is the body sent when pausing dispatch to a queue.
+type ClusterQueuePause struct {
+	Note string `json:"dispatch_paused_note,omitempty"`
+}
+
+// ClusterListOptions specifies the optional parameters to the
+// ClustersService.List method.
+type ClusterListOptions struct {
+	ListOptions
+}
+
+// ClusterQueueListOptions specifies the optional parameters to the
+// ClustersService.ListQueues method.
+type ClusterQueueListOptions struct {
+	ListOptions
+}
+
+// List the clusters for a given organisation.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#clusters-list-clusters
+func (cs *ClustersService) List(org string, opt *ClusterListOptions) ([]Cluster, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters", org)
+
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := cs.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clusters := new([]Cluster)
+	resp, err := cs.client.Do(req, clusters)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *clusters, resp, err
+}
+
+// Get fetches a cluster.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#clusters-get-a-cluster
+func (cs *ClustersService) Get(org string, id string) (*Cluster, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s", org, id)
+
+	req, err := cs.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cluster := new(Cluster)
+	resp, err := cs.client.Do(req, cluster)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cluster, resp, err
+}
+
+// Create - Creates a cluster for a given organisation.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#clusters-create-a-cluster
+func (cs *ClustersService) Create(org string, c *CreateCluster) (*Cluster, *Response, error) {
+	if c == nil {
+		return nil, nil, errors.New("cluster must not be nil")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters", org)
+
+	req, err := cs.client.NewRequest("POST", u, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cluster := new(Cluster)
+	resp, err := cs.client.Do(req, cluster)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cluster, resp, err
+}
+
+// Update - Updates a cluster. Only the non-nil fields of c are sent.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#clusters-update-a-cluster
+func (cs *ClustersService) Update(org string, id string, c *UpdateCluster) (*Cluster, *Response, error) {
+	if c == nil {
+		return nil, nil, errors.New("cluster must not be nil")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s", org, id)
+
+	req, err := cs.client.NewRequest("PATCH", u, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cluster := new(Cluster)
+	resp, err := cs.client.Do(req, cluster)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cluster, resp, err
+}
+
+// Delete a cluster.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#clusters-delete-a-cluster
+func (cs *ClustersService) Delete(org string, id string) (*Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s", org, id)
+
+	req, err := cs.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.client.Do(req, nil)
+}
+
+// ListQueues lists the queues of a cluster.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#cluster-queues-list-queues
+func (cs *ClustersService) ListQueues(org string, clusterID string, opt *ClusterQueueListOptions) ([]ClusterQueue, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s/queues", org, clusterID)
+
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := cs.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queues := new([]ClusterQueue)
+	resp, err := cs.client.Do(req, queues)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *queues, resp, err
+}
+
+// CreateQueue - Creates a queue in a cluster.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#cluster-queues-create-a-queue
+func (cs *ClustersService) CreateQueue(org string, clusterID string, q *CreateClusterQueue) (*ClusterQueue, *Response, error) {
+	if q == nil {
+		return nil, nil, errors.New("queue must not be nil")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s/queues", org, clusterID)
+
+	req, err := cs.client.NewRequest("POST", u, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queue := new(ClusterQueue)
+	resp, err := cs.client.Do(req, queue)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return queue, resp, err
+}
+
+// PauseQueue pauses dispatch of jobs to the agents of a cluster queue. The
+// note is optional and is shown alongside the paused queue.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#cluster-queues-pause-a-queue
+func (cs *ClustersService) PauseQueue(org string, clusterID string, queueID string, note string) (*ClusterQueue, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s/queues/%s/pause_dispatch", org, clusterID, queueID)
+
+	req, err := cs.client.NewRequest("POST", u, &ClusterQueuePause{Note: note})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queue := new(ClusterQueue)
+	resp, err := cs.client.Do(req, queue)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return queue, resp, err
+}
+
+// ResumeQueue resumes dispatch of jobs to the agents of a paused cluster queue.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/clusters#cluster-queues-resume-a-paused-queue
+func (cs *ClustersService) ResumeQueue(org string, clusterID string, queueID string) (*ClusterQueue, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/clusters/%s/queues/%s/resume_dispatch", org, clusterID, queueID)
+
+	req, err := cs.client.NewRequest("POST", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queue := new(ClusterQueue)
+	resp, err := cs.client.Do(req, queue)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return queue, resp, err
+}
@@ -0,0 +1,235 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClustersService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"page": "2"})
+		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+	})
+
+	clusters, _, err := client.Clusters.List("my-great-org", &ClusterListOptions{ListOptions{Page: 2}})
+	if err != nil {
+		t.Errorf("Clusters.List returned error: %v", err)
+	}
+
+	want := []Cluster{{ID: String("123")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(clusters, want) {
+		t.Errorf("Clusters.List returned %+v, want %+v", clusters, want)
+	}
+}
+
+func TestClustersService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123","name":"Platform","emoji":":rocket:","color":"#A9CCE3","default_queue_id":"456","created_at":"2015-03-04T02:26:54Z"}`)
+	})
+
+	cluster, _, err := client.Clusters.Get("my-great-org", "123")
+	if err != nil {
+		t.Errorf("Clusters.Get returned error: %v", err)
+	}
+
+	want := &Cluster{
+		ID:             String("123"),
+		Name:           String("Platform"),
+		Emoji:          String(":rocket:"),
+		Color:          String("#A9CCE3"),
+		DefaultQueueID: String("456"),
+		CreatedAt:      NewTimestamp(referenceTime),
+	}
+	if !reflect.DeepEqual(cluster, want) {
+		t.Errorf("Clusters.Get returned %+v, want %+v", cluster, want)
+	}
+}
+
+func TestClustersService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreateCluster{Name: "Platform", Description: "Platform team agents"}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreateCluster)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"id":"123","name":"Platform"}`)
+	})
+
+	cluster, _, err := client.Clusters.Create("my-great-org", input)
+	if err != nil {
+		t.Errorf("Clusters.Create returned error: %v", err)
+	}
+
+	want := &Cluster{ID: String("123"), Name: String("Platform")}
+	if !reflect.DeepEqual(cluster, want) {
+		t.Errorf("Clusters.Create returned %+v, want %+v", cluster, want)
+	}
+}
+
+func TestClustersService_Update(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "PATCH")
+
+		want := map[string]interface{}{"description": "Shared agents"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
+		fmt.Fprint(w, `{"id":"123","description":"Shared agents"}`)
+	})
+
+	cluster, _, err := client.Clusters.Update("my-great-org", "123", &UpdateCluster{Description: String("Shared agents")})
+	if err != nil {
+		t.Errorf("Clusters.Update returned error: %v", err)
+	}
+
+	want := &Cluster{ID: String("123"), Description: String("Shared agents")}
+	if !reflect.DeepEqual(cluster, want) {
+		t.Errorf("Clusters.Update returned %+v, want %+v", cluster, want)
+	}
+}
+
+func TestClustersService_Delete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Clusters.Delete("my-great-org", "123")
+	if err != nil {
+		t.Errorf("Clusters.Delete returned error: %v", err)
+	}
+}
+
+func TestClustersService_ListQueues(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123/queues", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":"456","key":"deploy","cluster_id":"123"}]`)
+	})
+
+	queues, _, err := client.Clusters.ListQueues("my-great-org", "123", nil)
+	if err != nil {
+		t.Errorf("Clusters.ListQueues returned error: %v", err)
+	}
+
+	want := []ClusterQueue{{ID: String("456"), Key: String("deploy"), ClusterID: String("123")}}
+	if !reflect.DeepEqual(queues, want) {
+		t.Errorf("Clusters.ListQueues returned %+v, want %+v", queues, want)
+	}
+}
+
+func TestClustersService_CreateQueue(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreateClusterQueue{Key: "deploy", Description: "Production deploys"}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123/queues", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreateClusterQueue)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"id":"456","key":"deploy"}`)
+	})
+
+	queue, _, err := client.Clusters.CreateQueue("my-great-org", "123", input)
+	if err != nil {
+		t.Errorf("Clusters.CreateQueue returned error: %v", err)
+	}
+
+	want := &ClusterQueue{ID: String("456"), Key: String("deploy")}
+	if !reflect.DeepEqual(queue, want) {
+		t.Errorf("Clusters.CreateQueue returned %+v, want %+v", queue, want)
+	}
+}
+
+func TestClustersService_PauseQueue(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123/queues/456/pause_dispatch", func(w http.ResponseWriter, r *http.Request) {
+		v := new(ClusterQueuePause)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		want := &ClusterQueuePause{Note: "Production freeze"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
+		fmt.Fprint(w, `{"id":"456","key":"deploy","dispatch_paused":true,"dispatch_paused_note":"Production freeze"}`)
+	})
+
+	queue, _, err := client.Clusters.PauseQueue("my-great-org", "123", "456", "Production freeze")
+	if err != nil {
+		t.Errorf("Clusters.PauseQueue returned error: %v", err)
+	}
+
+	want := &ClusterQueue{
+		ID:                 String("456"),
+		Key:                String("deploy"),
+		DispatchPaused:     true,
+		DispatchPausedNote: String("Production freeze"),
+	}
+	if !reflect.DeepEqual(queue, want) {
+		t.Errorf("Clusters.PauseQueue returned %+v, want %+v", queue, want)
+	}
+}
+
+func TestClustersService_ResumeQueue(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/clusters/123/queues/456/resume_dispatch", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"id":"456","key":"deploy","dispatch_paused":false}`)
+	})
+
+	queue, _, err := client.Clusters.ResumeQueue("my-great-org", "123", "456")
+	if err != nil {
+		t.Errorf("Clusters.ResumeQueue returned error: %v", err)
+	}
+
+	want := &ClusterQueue{ID: String("456"), Key: String("deploy")}
+	if !reflect.DeepEqual(queue, want) {
+		t.Errorf("Clusters.ResumeQueue returned %+v, want %+v", queue, want)
+	}
+}
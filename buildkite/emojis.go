@@ -0,0 +1,45 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import "fmt"
+
+// EmojisService handles communication with the emoji related
+// methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/emojis
+type EmojisService struct {
+	client *Client
+}
+
+// Emoji emoji, what else can you say?
+type Emoji struct {
+	Name *string `json:"name,omitempty"`
+	URL  *string `json:"url,omitempty"`
+}
+
+// List all the emojis for a given organisation, including custom emojis and aliases.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/emojis#list-emojis
+func (es *EmojisService) List(org string) ([]Emoji, *Response, error) {
+
+	var u string
+
+	u = fmt.Sprintf("v2/organizations/%s/emojis", org)
+
+	req, err := es.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emoji := new([]Emoji)
+	resp, err := es.client.Do(req, emoji)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *emoji, resp, nil
+}
@@ -0,0 +1,28 @@
+package buildkite
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEmojisService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/emojis", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"name":"partyparrot","url":"https://buildkiteassets.com/emojis/img-buildkite-64/partyparrot.gif"}]`)
+	})
+
+	emoji, _, err := client.Emojis.List("my-great-org")
+	if err != nil {
+		t.Errorf("Emojis.List returned error: %v", err)
+	}
+
+	want := []Emoji{{Name: String("partyparrot"), URL: String("https://buildkiteassets.com/emojis/img-buildkite-64/partyparrot.gif")}}
+	if !reflect.DeepEqual(want, emoji) {
+		t.Errorf("Emojis.List returned %+v, want %+v", emoji, want)
+	}
+}
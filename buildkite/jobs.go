@@ -1,7 +1,11 @@
 package buildkite
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 )
 
 // JobsService handles communication with the job related
@@ -12,6 +16,35 @@ type JobsService struct {
 	client *Client
 }
 
+// JobState is the state of a job.
+type JobState string
+
+// The states a job can be in.
+const (
+	JobStatePending         JobState = "pending"
+	JobStateWaiting         JobState = "waiting"
+	JobStateWaitingFailed   JobState = "waiting_failed"
+	JobStateBlocked         JobState = "blocked"
+	JobStateBlockedFailed   JobState = "blocked_failed"
+	JobStateUnblocked       JobState = "unblocked"
+	JobStateUnblockedFailed JobState = "unblocked_failed"
+	JobStateLimiting        JobState = "limiting"
+	JobStateLimited         JobState = "limited"
+	JobStateScheduled       JobState = "scheduled"
+	JobStateAssigned        JobState = "assigned"
+	JobStateAccepted        JobState = "accepted"
+	JobStateRunning         JobState = "running"
+	JobStatePassed          JobState = "passed"
+	JobStateFailed          JobState = "failed"
+	JobStateCanceling       JobState = "canceling"
+	JobStateCanceled        JobState = "canceled"
+	JobStateTimingOut       JobState = "timing_out"
+	JobStateTimedOut        JobState = "timed_out"
+	JobStateSkipped         JobState = "skipped"
+	JobStateBroken          JobState = "broken"
+	JobStateExpired         JobState = "expired"
+)
+
 // Job represents a job run during a build in buildkite
 type Job struct {
 	ID              *string    `json:"id,omitempty"`
@@ -21,7 +54,12 @@ type Job struct {
 	LogsURL         *string    `json:"logs_url,omitempty"`
 	RawLogsURL      *string    `json:"raw_log_url,omitempty"`
 	Command         *string    `json:"command,omitempty"`
+	StepKey         *string    `json:"step_key,omitempty"`
 	ExitStatus      *int       `json:"exit_status,omitempty"`
+	SoftFailed      *bool      `json:"soft_failed,omitempty"`
+	Retried         *bool      `json:"retried,omitempty"`
+	RetriedInJobID  *string    `json:"retried_in_job_id,omitempty"`
+	RetriesCount    *int       `json:"retries_count,omitempty"`
 	ArtifactPaths   *string    `json:"artifact_paths,omitempty"`
 	CreatedAt       *Timestamp `json:"created_at,omitempty"`
 	ScheduledAt     *Timestamp `json:"scheduled_at,omitempty"`
@@ -33,25 +71,86 @@ type Job struct {
 	WebURL          string     `json:"web_url"`
 }
 
+func (j Job) String() string {
+	return Stringify(j)
+}
+
+// hasFailed reports whether the job failed or timed out.
+func (j *Job) hasFailed() bool {
+	if j.State == nil {
+		return false
+	}
+	switch JobState(*j.State) {
+	case JobStateFailed, JobStateTimedOut:
+		return true
+	}
+	return false
+}
+
+// JobLog represents a job log output
+type JobLog struct {
+	URL         *string `json:"url,omitempty"`
+	Content     string  `json:"content"`
+	Size        int     `json:"size"`
+	HeaderTimes []int64 `json:"header_times,omitempty"`
+}
+
+// JobLogFormat is the format a job log is requested in
+type JobLogFormat string
+
+// The log formats served by the job log endpoint, used as the Accept header.
+const (
+	JobLogFormatJSON JobLogFormat = "application/json"
+	JobLogFormatText JobLogFormat = "text/plain"
+	JobLogFormatHTML JobLogFormat = "text/html"
+)
+
 // JobUnblockOptions specifies the optional parameters to UnblockJob
 type JobUnblockOptions struct {
+	// Values for the fields of the block step, keyed by field key
 	Fields map[string]string `json:"fields,omitempty"`
+
+	// ID of the user to unblock the job on behalf of, defaults to the token owner
+	Unblocker string `json:"unblocker,omitempty"`
 }
 
 // UnblockJob - unblock a job
 //
+// The options, if supplied, are sent as the request body.
+//
 // buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#unblock-a-job
 func (js *JobsService) UnblockJob(org string, pipeline string, buildNumber string, jobID string, opt *JobUnblockOptions) (*Job, *Response, error) {
+	return js.unblockJob(context.Background(), org, pipeline, buildNumber, jobID, opt)
+}
+
+func (js *JobsService) unblockJob(ctx context.Context, org string, pipeline string, buildNumber string, jobID string, opt *JobUnblockOptions) (*Job, *Response, error) {
 	var u string
 
 	u = fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/unblock", org, pipeline, buildNumber, jobID)
 
-	u, err := addOptions(u, opt)
+	req, err := js.client.NewRequestWithContext(ctx, "PUT", u, opt)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := js.client.NewRequest("PUT", u, opt)
+	job := new(Job)
+	resp, err := js.client.Do(req, job)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return job, resp, err
+}
+
+// RetryJob - retry a job
+//
+// The returned Job is the newly created retry job, with its own ID and state.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#retry-a-job
+func (js *JobsService) RetryJob(org string, pipeline string, buildNumber string, jobID string) (*Job, *Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/retry", org, pipeline, buildNumber, jobID)
+
+	req, err := js.client.NewRequest("PUT", u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -64,3 +163,86 @@ func (js *JobsService) UnblockJob(org string, pipeline string, buildNumber strin
 
 	return job, resp, err
 }
+
+// GetJobLog - get a job's log output
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#get-a-jobs-log-output
+func (js *JobsService) GetJobLog(org string, pipeline string, buildNumber string, jobID string) (*JobLog, *Response, error) {
+	return js.GetJobLogWithFormat(org, pipeline, buildNumber, jobID, JobLogFormatJSON)
+}
+
+// GetJobLogWithFormat - get a job's log output in the given format
+//
+// For JobLogFormatText and JobLogFormatHTML the raw body is stored in the
+// Content of the returned JobLog. The entire log is buffered in memory, so for
+// very large logs use StreamJobLog instead.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#get-a-jobs-log-output
+func (js *JobsService) GetJobLogWithFormat(org string, pipeline string, buildNumber string, jobID string, format JobLogFormat) (*JobLog, *Response, error) {
+	req, err := js.newJobLogRequest(org, pipeline, buildNumber, jobID, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log := new(JobLog)
+
+	if format == JobLogFormatJSON {
+		resp, err := js.client.Do(req, log)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		return log, resp, err
+	}
+
+	buf := new(bytes.Buffer)
+	resp, err := js.client.Do(req, buf)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	log.Content = buf.String()
+	log.Size = buf.Len()
+
+	return log, resp, err
+}
+
+// StreamJobLog - write a job's log output in the given format to w without
+// buffering it in memory
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#get-a-jobs-log-output
+func (js *JobsService) StreamJobLog(org string, pipeline string, buildNumber string, jobID string, format JobLogFormat, w io.Writer) (*Response, error) {
+	req, err := js.newJobLogRequest(org, pipeline, buildNumber, jobID, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return js.client.Do(req, w)
+}
+
+// DeleteJobLog - delete a job's log output, e.g. when it holds sensitive
+// information
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/jobs#delete-a-jobs-log-output
+func (js *JobsService) DeleteJobLog(org string, pipeline string, buildNumber string, jobID string) (*Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/log", org, pipeline, buildNumber, jobID)
+
+	req, err := js.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return js.client.Do(req, nil)
+}
+
+func (js *JobsService) newJobLogRequest(org string, pipeline string, buildNumber string, jobID string, format JobLogFormat) (*http.Request, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/log", org, pipeline, buildNumber, jobID)
+
+	req, err := js.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(format))
+
+	return req, nil
+}
@@ -1,6 +1,8 @@
 package buildkite
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -29,3 +31,216 @@ func TestJobsService_UnblockJob(t *testing.T) {
 		t.Errorf("UnblockJob returned %+v, want %+v", job, want)
 	}
 }
+
+func TestJobsService_RetryJob(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/retry", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		fmt.Fprint(w, `{
+  "id": "awesome-retried-job-id",
+  "state": "scheduled"
+}`)
+	})
+
+	job, _, err := client.Jobs.RetryJob("my-great-org", "sup-keith", "awesome-build", "awesome-job-id")
+	if err != nil {
+		t.Errorf("RetryJob returned error: %v", err)
+	}
+
+	want := &Job{ID: String("awesome-retried-job-id"), State: String("scheduled")}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("RetryJob returned %+v, want %+v", job, want)
+	}
+}
+
+func TestJobsService_UnblockJob_fields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &JobUnblockOptions{Fields: map[string]string{"release-version": "1.2.3"}}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/unblock", func(w http.ResponseWriter, r *http.Request) {
+		v := new(JobUnblockOptions)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "PUT")
+		testFormValues(t, r, values{})
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{
+  "id": "awesome-job-id",
+  "state": "unblocked"
+}`)
+	})
+
+	job, _, err := client.Jobs.UnblockJob("my-great-org", "sup-keith", "awesome-build", "awesome-job-id", input)
+	if err != nil {
+		t.Errorf("UnblockJob returned error: %v", err)
+	}
+
+	want := &Job{ID: String("awesome-job-id"), State: String("unblocked")}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("UnblockJob returned %+v, want %+v", job, want)
+	}
+}
+
+func TestJobsService_GetJobLog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got, want := r.Header.Get("Accept"), "application/json"; got != want {
+			t.Errorf("Accept header is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, `{
+  "url": "https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log",
+  "content": "This is the job's log output",
+  "size": 28,
+  "header_times": [1563337899810051000, 1563337899811015000]
+}`)
+	})
+
+	log, _, err := client.Jobs.GetJobLog("my-great-org", "sup-keith", "awesome-build", "awesome-job-id")
+	if err != nil {
+		t.Errorf("GetJobLog returned error: %v", err)
+	}
+
+	want := &JobLog{
+		URL:         String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log"),
+		Content:     "This is the job's log output",
+		Size:        28,
+		HeaderTimes: []int64{1563337899810051000, 1563337899811015000},
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("GetJobLog returned %+v, want %+v", log, want)
+	}
+}
+
+func TestJobsService_GetJobLogWithFormat_text(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got, want := r.Header.Get("Accept"), "text/plain"; got != want {
+			t.Errorf("Accept header is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, "This is the job's log output")
+	})
+
+	log, _, err := client.Jobs.GetJobLogWithFormat("my-great-org", "sup-keith", "awesome-build", "awesome-job-id", JobLogFormatText)
+	if err != nil {
+		t.Errorf("GetJobLogWithFormat returned error: %v", err)
+	}
+
+	want := &JobLog{Content: "This is the job's log output", Size: 28}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("GetJobLogWithFormat returned %+v, want %+v", log, want)
+	}
+}
+
+func TestJobsService_GetJobLogWithFormat_gzip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		writeGzipped(t, w, "This is the job's log output")
+	})
+
+	log, _, err := client.Jobs.GetJobLogWithFormat("my-great-org", "sup-keith", "awesome-build", "awesome-job-id", JobLogFormatText)
+	if err != nil {
+		t.Errorf("GetJobLogWithFormat returned error: %v", err)
+	}
+
+	want := &JobLog{Content: "This is the job's log output", Size: 28}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("GetJobLogWithFormat returned %+v, want %+v", log, want)
+	}
+}
+
+func TestJobsService_StreamJobLog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got, want := r.Header.Get("Accept"), "text/html"; got != want {
+			t.Errorf("Accept header is %v, want %v", got, want)
+		}
+		fmt.Fprint(w, "<p>This is the job's log output</p>")
+	})
+
+	buf := new(bytes.Buffer)
+	_, err := client.Jobs.StreamJobLog("my-great-org", "sup-keith", "awesome-build", "awesome-job-id", JobLogFormatHTML, buf)
+	if err != nil {
+		t.Errorf("StreamJobLog returned error: %v", err)
+	}
+
+	if got, want := buf.String(), "<p>This is the job's log output</p>"; got != want {
+		t.Errorf("StreamJobLog wrote %q, want %q", got, want)
+	}
+}
+
+func TestJobsService_DeleteJobLog(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/awesome-build/jobs/awesome-job-id/log", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := client.Jobs.DeleteJobLog("my-great-org", "sup-keith", "awesome-build", "awesome-job-id")
+	if err != nil {
+		t.Errorf("DeleteJobLog returned error: %v", err)
+	}
+
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Errorf("DeleteJobLog returned status %d, want %d", got, want)
+	}
+}
+
+func TestJob_unmarshal(t *testing.T) {
+	data := `{
+  "id": "b63254c0-3271-4a98-8270-7cfbd6c2f14e",
+  "type": "script",
+  "name": ":rspec:",
+  "step_key": "specs",
+  "state": "failed",
+  "exit_status": 1,
+  "soft_failed": false,
+  "retried": true,
+  "retried_in_job_id": "e7ac6c29-3e8e-4b7e-9d89-9c4a2c54e9b1",
+  "retries_count": 1,
+  "created_at": "2015-03-04T02:26:54Z"
+}`
+
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+
+	want := Job{
+		ID:             String("b63254c0-3271-4a98-8270-7cfbd6c2f14e"),
+		Type:           String("script"),
+		Name:           String(":rspec:"),
+		StepKey:        String("specs"),
+		State:          String("failed"),
+		ExitStatus:     Int(1),
+		SoftFailed:     Bool(false),
+		Retried:        Bool(true),
+		RetriedInJobID: String("e7ac6c29-3e8e-4b7e-9d89-9c4a2c54e9b1"),
+		RetriesCount:   Int(1),
+		CreatedAt:      NewTimestamp(referenceTime),
+	}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("json.Unmarshal returned %+v, want %+v", job, want)
+	}
+}
@@ -25,6 +25,7 @@ type Organization struct {
 	Repository   *string    `json:"repository,omitempty"`
 	PipelinesURL *string    `json:"pipelines_url,omitempty"`
 	AgentsURL    *string    `json:"agents_url,omitempty"`
+	EmojisURL    *string    `json:"emojis_url,omitempty"`
 	CreatedAt    *Timestamp `json:"created_at,omitempty"`
 }
 
@@ -46,3 +46,26 @@ func TestOrganizationsService_Get(t *testing.T) {
 		t.Errorf("Organizations.Get returned %+v, want %+v", org, want)
 	}
 }
+
+func TestOrganizationsService_Get_emojisURL(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/babelstoemp", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123","emojis_url":"https://api.buildkite.com/v2/organizations/babelstoemp/emojis"}`)
+	})
+
+	org, _, err := client.Organizations.Get("babelstoemp")
+	if err != nil {
+		t.Errorf("Organizations.Get returned error: %v", err)
+	}
+
+	want := &Organization{
+		ID:        String("123"),
+		EmojisURL: String("https://api.buildkite.com/v2/organizations/babelstoemp/emojis"),
+	}
+	if !reflect.DeepEqual(org, want) {
+		t.Errorf("Organizations.Get returned %+v, want %+v", org, want)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import "encoding/json"
+
+// PipelineStep is a step of a pipeline's configuration: a *CommandStep,
+// *WaitStep, *BlockStep, *TriggerStep or *GroupStep.
+//
+// buildkite docs: https://buildkite.com/docs/pipelines/defining-steps
+type PipelineStep interface {
+	pipelineStep()
+}
+
+// PipelineSteps is the list of steps in a pipeline's configuration. Set it
+// as UpdatePipeline.Steps, or upload it with PipelinesService.UploadSteps:
+//
+//	_, _, err := client.Pipelines.UploadSteps(org, slug, buildkite.PipelineSteps{
+//		&buildkite.CommandStep{Label: ":hammer:", Command: "make test"},
+//		&buildkite.WaitStep{},
+//		&buildkite.TriggerStep{Trigger: "deploy"},
+//	})
+type PipelineSteps []PipelineStep
+
+// Configuration returns the steps as a pipeline configuration, for
+// CreatePipeline.Configuration or UpdatePipeline.Configuration. The
+// configuration is JSON, which is also valid YAML.
+func (s PipelineSteps) Configuration() (string, error) {
+	data, err := json.Marshal(struct {
+		Steps PipelineSteps `json:"steps"`
+	}{s})
+	return string(data), err
+}
+
+// CommandStep runs one or more shell commands on an agent.
+type CommandStep struct {
+	Label                  string            `json:"label,omitempty"`
+	Key                    string            `json:"key,omitempty"`
+	Command                string            `json:"command,omitempty"`
+	Commands               []string          `json:"commands,omitempty"`
+	Agents                 map[string]string `json:"agents,omitempty"`
+	Env                    map[string]string `json:"env,omitempty"`
+	ArtifactPaths          []string          `json:"artifact_paths,omitempty"`
+	Branches               string            `json:"branches,omitempty"`
+	If                     string            `json:"if,omitempty"`
+	DependsOn              []string          `json:"depends_on,omitempty"`
+	AllowDependencyFailure bool              `json:"allow_dependency_failure,omitempty"`
+	Parallelism            int               `json:"parallelism,omitempty"`
+	TimeoutInMinutes       int               `json:"timeout_in_minutes,omitempty"`
+	SoftFail               bool              `json:"soft_fail,omitempty"`
+}
+
+// WaitStep waits for all previous steps to pass before continuing.
+type WaitStep struct {
+	// ContinueOnFailure continues even if previous steps failed.
+	ContinueOnFailure bool
+	If                string
+	DependsOn         []string
+}
+
+// MarshalJSON implements the json.Marshaler interface. A wait step with no
+// options is written as the bare string "wait".
+func (s WaitStep) MarshalJSON() ([]byte, error) {
+	if !s.ContinueOnFailure && s.If == "" && len(s.DependsOn) == 0 {
+		return json.Marshal("wait")
+	}
+	return json.Marshal(struct {
+		Wait              *string  `json:"wait"`
+		ContinueOnFailure bool     `json:"continue_on_failure,omitempty"`
+		If                string   `json:"if,omitempty"`
+		DependsOn         []string `json:"depends_on,omitempty"`
+	}{nil, s.ContinueOnFailure, s.If, s.DependsOn})
+}
+
+// BlockStep pauses the build until it's unblocked, optionally asking for
+// input through its fields.
+type BlockStep struct {
+	// Block is the label of the step.
+	Block     string       `json:"block"`
+	Key       string       `json:"key,omitempty"`
+	Prompt    string       `json:"prompt,omitempty"`
+	Fields    []BlockField `json:"fields,omitempty"`
+	Branches  string       `json:"branches,omitempty"`
+	If        string       `json:"if,omitempty"`
+	DependsOn []string     `json:"depends_on,omitempty"`
+}
+
+// BlockField is an input field of a block step: a text field when Text is
+// set, or a select field when Select is set.
+type BlockField struct {
+	Text     string             `json:"text,omitempty"`
+	Select   string             `json:"select,omitempty"`
+	Key      string             `json:"key"`
+	Hint     string             `json:"hint,omitempty"`
+	Required *bool              `json:"required,omitempty"`
+	Default  string             `json:"default,omitempty"`
+	Options  []BlockFieldOption `json:"options,omitempty"`
+}
+
+// BlockFieldOption is an option of a select field.
+type BlockFieldOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// TriggerStep creates a build on another pipeline.
+type TriggerStep struct {
+	// Trigger is the slug of the pipeline to build.
+	Trigger   string        `json:"trigger"`
+	Label     string        `json:"label,omitempty"`
+	Key       string        `json:"key,omitempty"`
+	Async     bool          `json:"async,omitempty"`
+	Build     *TriggerBuild `json:"build,omitempty"`
+	Branches  string        `json:"branches,omitempty"`
+	If        string        `json:"if,omitempty"`
+	DependsOn []string      `json:"depends_on,omitempty"`
+}
+
+// TriggerBuild sets the attributes of the build made by a trigger step.
+type TriggerBuild struct {
+	Message  string            `json:"message,omitempty"`
+	Commit   string            `json:"commit,omitempty"`
+	Branch   string            `json:"branch,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	MetaData map[string]string `json:"meta_data,omitempty"`
+}
+
+// GroupStep groups steps together under a label.
+type GroupStep struct {
+	// Group is the label of the group.
+	Group     string        `json:"group"`
+	Key       string        `json:"key,omitempty"`
+	If        string        `json:"if,omitempty"`
+	DependsOn []string      `json:"depends_on,omitempty"`
+	Steps     PipelineSteps `json:"steps"`
+}
+
+func (*CommandStep) pipelineStep() {}
+func (*WaitStep) pipelineStep()    {}
+func (*BlockStep) pipelineStep()   {}
+func (*TriggerStep) pipelineStep() {}
+func (*GroupStep) pipelineStep()   {}
@@ -0,0 +1,80 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPipelineStep_marshal(t *testing.T) {
+	tests := []struct {
+		desc string
+		step PipelineStep
+		want string
+	}{
+		{
+			"command",
+			&CommandStep{Label: ":hammer: Tests", Key: "tests", Command: "make test", Agents: map[string]string{"queue": "builders"}, Parallelism: 4},
+			`{"label":":hammer: Tests","key":"tests","command":"make test","agents":{"queue":"builders"},"parallelism":4}`,
+		},
+		{
+			"commands",
+			&CommandStep{Commands: []string{"make", "make test"}, SoftFail: true},
+			`{"commands":["make","make test"],"soft_fail":true}`,
+		},
+		{
+			"wait",
+			&WaitStep{},
+			`"wait"`,
+		},
+		{
+			"wait with options",
+			&WaitStep{ContinueOnFailure: true},
+			`{"wait":null,"continue_on_failure":true}`,
+		},
+		{
+			"block",
+			&BlockStep{Block: ":rocket: Release", Prompt: "Release it?", Fields: []BlockField{
+				{Select: "Environment", Key: "env", Required: Bool(true), Options: []BlockFieldOption{{Label: "Production", Value: "production"}}},
+				{Text: "Notes", Key: "notes"},
+			}},
+			`{"block":":rocket: Release","prompt":"Release it?","fields":[{"select":"Environment","key":"env","required":true,"options":[{"label":"Production","value":"production"}]},{"text":"Notes","key":"notes"}]}`,
+		},
+		{
+			"trigger",
+			&TriggerStep{Trigger: "deploy", Async: true, Build: &TriggerBuild{Branch: "main", Env: map[string]string{"DIGEST": "sha256:abc"}}},
+			`{"trigger":"deploy","async":true,"build":{"branch":"main","env":{"DIGEST":"sha256:abc"}}}`,
+		},
+		{
+			"group",
+			&GroupStep{Group: ":lint-roller: Lint", Steps: PipelineSteps{&CommandStep{Command: "make lint"}, &WaitStep{}}},
+			`{"group":":lint-roller: Lint","steps":[{"command":"make lint"},"wait"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.step)
+		if err != nil {
+			t.Errorf("%s: json.Marshal returned error: %v", tt.desc, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: json.Marshal returned %s, want %s", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestPipelineSteps_Configuration(t *testing.T) {
+	config, err := PipelineSteps{
+		&CommandStep{Command: "make test"},
+		&WaitStep{},
+		&TriggerStep{Trigger: "deploy"},
+	}.Configuration()
+	if err != nil {
+		t.Fatalf("Configuration returned error: %v", err)
+	}
+
+	want := `{"steps":[{"command":"make test"},"wait",{"trigger":"deploy"}]}`
+	if config != want {
+		t.Errorf("Configuration returned %s, want %s", config, want)
+	}
+}
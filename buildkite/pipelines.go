@@ -6,8 +6,11 @@
 package buildkite
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 )
 
 // PipelinesService handles communication with the pipeline related
@@ -22,9 +25,13 @@ type PipelinesService struct {
 type CreatePipeline struct {
 	Name       string `json:"name"`
 	Repository string `json:"repository"`
-	Steps      []Step `json:"steps"`
+
+	// Either configuration needs to be specified as a yaml string or steps.
+	Configuration string `json:"configuration,omitempty"`
+	Steps         []Step `json:"steps,omitempty"`
 
 	// Optional fields
+	DefaultBranch                   string            `json:"default_branch,omitempty"`
 	Description                     string            `json:"description,omitempty"`
 	Env                             map[string]string `json:"env,omitempty"`
 	ProviderSettings                ProviderSettings  `json:"provider_settings,omitempty"`
@@ -34,6 +41,63 @@ type CreatePipeline struct {
 	CancelRunningBranchBuilds       bool              `json:"cancel_running_branch_builds,omitempty"`
 	CancelRunningBranchBuildsFilter string            `json:"cancel_running_branch_builds_filter,omitempty"`
 	TeamUuids                       []string          `json:"team_uuids,omitempty"`
+	Tags                            []string          `json:"tags,omitempty"`
+	Visibility                      string            `json:"visibility,omitempty"`
+}
+
+// UpdatePipeline - Update a Pipeline. Fields left nil are not changed.
+//
+// Steps replaces the pipeline's configuration with the given steps, and
+// can't be combined with Configuration.
+type UpdatePipeline struct {
+	Name                            *string           `json:"name,omitempty"`
+	Repository                      *string           `json:"repository,omitempty"`
+	Configuration                   *string           `json:"configuration,omitempty"`
+	Steps                           PipelineSteps     `json:"-"`
+	DefaultBranch                   *string           `json:"default_branch,omitempty"`
+	Description                     *string           `json:"description,omitempty"`
+	Env                             map[string]string `json:"env,omitempty"`
+	ProviderSettings                ProviderSettings  `json:"provider_settings,omitempty"`
+	BranchConfiguration             *string           `json:"branch_configuration,omitempty"`
+	SkipQueuedBranchBuilds          *bool             `json:"skip_queued_branch_builds,omitempty"`
+	SkipQueuedBranchBuildsFilter    *string           `json:"skip_queued_branch_builds_filter,omitempty"`
+	CancelRunningBranchBuilds       *bool             `json:"cancel_running_branch_builds,omitempty"`
+	CancelRunningBranchBuildsFilter *string           `json:"cancel_running_branch_builds_filter,omitempty"`
+	Tags                            []string          `json:"tags,omitempty"`
+	Visibility                      *string           `json:"visibility,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. Steps are sent as the
+// pipeline's configuration, in the form {"steps": [...]}.
+func (p UpdatePipeline) MarshalJSON() ([]byte, error) {
+	type updatePipeline UpdatePipeline
+	v := updatePipeline(p)
+	if len(p.Steps) > 0 {
+		if p.Configuration != nil {
+			return nil, errors.New("pipeline configuration and steps must not both be set")
+		}
+		config, err := p.Steps.Configuration()
+		if err != nil {
+			return nil, err
+		}
+		v.Configuration = &config
+	}
+	return json.Marshal(v)
+}
+
+// Pipeline visibilities, see Pipeline.Visibility.
+const (
+	PipelineVisibilityPrivate = "private"
+	PipelineVisibilityPublic  = "public"
+)
+
+// validateVisibility checks v is one of the PipelineVisibility constants.
+func validateVisibility(v string) error {
+	switch v {
+	case PipelineVisibilityPrivate, PipelineVisibilityPublic:
+		return nil
+	}
+	return fmt.Errorf("pipeline visibility must be %q or %q, not %q", PipelineVisibilityPrivate, PipelineVisibilityPublic, v)
 }
 
 // Pipeline represents a buildkite pipeline.
@@ -48,6 +112,15 @@ type Pipeline struct {
 	BadgeURL   *string    `json:"badge_url,omitempty"`
 	CreatedAt  *Timestamp `json:"created_at,omitempty"`
 
+	Description         *string  `json:"description,omitempty"`
+	DefaultBranch       *string  `json:"default_branch,omitempty"`
+	BranchConfiguration *string  `json:"branch_configuration,omitempty"`
+	Configuration       *string  `json:"configuration,omitempty"`
+	Tags                []string `json:"tags,omitempty"`
+	Archived            *bool    `json:"archived,omitempty"`
+	Visibility          *string  `json:"visibility,omitempty"`
+
+	// the current depth of the pipeline's queue, as of the request
 	ScheduledBuildsCount *int `json:"scheduled_builds_count,omitempty"`
 	RunningBuildsCount   *int `json:"running_builds_count,omitempty"`
 	ScheduledJobsCount   *int `json:"scheduled_jobs_count,omitempty"`
@@ -61,6 +134,10 @@ type Pipeline struct {
 	Steps []*Step `json:"steps,omitempty"`
 }
 
+func (p Pipeline) String() string {
+	return Stringify(p)
+}
+
 // Step represents a build step in buildkites build pipeline
 type Step struct {
 	Type                *string           `json:"type,omitempty"`
@@ -83,6 +160,18 @@ type PipelineListOptions struct {
 //
 // buildkite API docs: https://buildkite.com/docs/rest-api/pipelines#create-a-pipeline
 func (ps *PipelinesService) Create(org string, p *CreatePipeline) (*Pipeline, *Response, error) {
+	if p == nil {
+		return nil, nil, errors.New("pipeline must not be nil")
+	}
+	if p.Configuration == "" && len(p.Steps) == 0 {
+		return nil, nil, errors.New("pipeline must have either configuration or steps")
+	}
+	if p.Visibility != "" {
+		if err := validateVisibility(p.Visibility); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	u := fmt.Sprintf("v2/organizations/%s/pipelines", org)
 
 	req, err := ps.client.NewRequest("POST", u, p)
@@ -120,6 +209,45 @@ func (ps *PipelinesService) Get(org string, slug string) (*Pipeline, *Response,
 	return pipeline, resp, err
 }
 
+// GetBadge fetches the SVG status badge of a pipeline, from its BadgeURL,
+// showing the status of the latest build on branch, or of the default branch
+// when branch is empty.
+//
+// buildkite docs: https://buildkite.com/docs/integrations/build-status-badges
+func (ps *PipelinesService) GetBadge(org string, slug string, branch string) ([]byte, *Response, error) {
+	pipeline, resp, err := ps.Get(org, slug)
+	if err != nil {
+		return nil, resp, err
+	}
+	if pipeline.BadgeURL == nil {
+		return nil, resp, fmt.Errorf("pipeline %s has no badge URL", slug)
+	}
+
+	u, err := url.Parse(*pipeline.BadgeURL)
+	if err != nil {
+		return nil, resp, err
+	}
+	if branch != "" {
+		q := u.Query()
+		q.Set("branch", branch)
+		u.RawQuery = q.Encode()
+	}
+
+	// the badge is served from its own host, so the API token isn't sent
+	req, err := ps.client.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	badge := new(bytes.Buffer)
+	resp, err = ps.client.Do(req, badge)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return badge.Bytes(), resp, err
+}
+
 // List the pipelines for a given organisation.
 //
 // buildkite API docs: https://buildkite.com/docs/api/pipelines#list-pipelines
@@ -162,38 +290,77 @@ func (ps *PipelinesService) Delete(org string, slug string) (*Response, error) {
 	return ps.client.Do(req, nil)
 }
 
-// Update - Updates a pipeline.
+// Update - Updates a pipeline. Only the non-nil fields of p are sent, so
+// the remaining settings of the pipeline are left untouched.
 //
 // buildkite API docs: https://buildkite.com/docs/rest-api/pipelines#update-a-pipeline
-func (ps *PipelinesService) Update(org string, p *Pipeline) (*Response, error) {
+func (ps *PipelinesService) Update(org string, slug string, p *UpdatePipeline) (*Pipeline, *Response, error) {
 	if p == nil {
-		return nil, errors.New("pipeline must not be nil")
+		return nil, nil, errors.New("pipeline must not be nil")
+	}
+	if p.Visibility != nil {
+		if err := validateVisibility(*p.Visibility); err != nil {
+			return nil, nil, err
+		}
 	}
+	if len(p.Steps) > 0 && p.Configuration != nil {
+		return nil, nil, errors.New("pipeline configuration and steps must not both be set")
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s", org, slug)
 
-	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s", org, *p.Slug)
+	req, err := ps.client.NewRequest("PATCH", u, p)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// There is quite a lot of properties that are not represented by the Client-side
-	// Pipeline abstraction hence only a subset can be updated.
-	cp := &CreatePipeline{
-		Name:             *p.Name,
-		Repository:       *p.Repository,
-		Steps:            make([]Step, len(p.Steps)),
-		ProviderSettings: p.Provider.Settings,
+	pipeline := new(Pipeline)
+	resp, err := ps.client.Do(req, pipeline)
+	if err != nil {
+		return nil, resp, err
 	}
 
-	for i := range p.Steps {
-		cp.Steps[i] = *p.Steps[i]
+	return pipeline, resp, err
+}
+
+// UploadSteps - Replaces the steps of a pipeline, e.g. from an orchestrator
+// outside of a build. It's short for Update with UpdatePipeline.Steps set.
+//
+// buildkite API docs: https://buildkite.com/docs/rest-api/pipelines#update-a-pipeline
+func (ps *PipelinesService) UploadSteps(org string, slug string, steps PipelineSteps) (*Pipeline, *Response, error) {
+	if len(steps) == 0 {
+		return nil, nil, errors.New("steps must not be empty")
 	}
+	return ps.Update(org, slug, &UpdatePipeline{Steps: steps})
+}
+
+// Archive - Archives a pipeline, hiding it without deleting its builds.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/pipelines#archive-a-pipeline
+func (ps *PipelinesService) Archive(org string, slug string) (*Pipeline, *Response, error) {
+	return ps.setArchived(org, slug, "archive")
+}
+
+// Unarchive - Restores an archived pipeline.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/pipelines#unarchive-a-pipeline
+func (ps *PipelinesService) Unarchive(org string, slug string) (*Pipeline, *Response, error) {
+	return ps.setArchived(org, slug, "unarchive")
+}
+
+func (ps *PipelinesService) setArchived(org string, slug string, action string) (*Pipeline, *Response, error) {
+	u := fmt.Sprintf("v2/organizations/%s/pipelines/%s/%s", org, slug, action)
 
-	req, err := ps.client.NewRequest("PATCH", u, cp)
+	req, err := ps.client.NewRequest("POST", u, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	resp, err := ps.client.Do(req, p)
+	pipeline := new(Pipeline)
+	resp, err := ps.client.Do(req, pipeline)
 	if err != nil {
-		return resp, err
+		return nil, resp, err
 	}
 
-	return resp, err
+	return pipeline, resp, err
 }
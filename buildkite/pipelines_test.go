@@ -28,6 +28,36 @@ func TestPipelinesService_List(t *testing.T) {
 	}
 }
 
+func TestPipelinesService_List_paged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"page":     "2",
+			"per_page": "2",
+		})
+		w.Header().Set("Link", `<https://api.buildkite.com/v2/organizations/my-great-org/pipelines?page=3&per_page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id":"123"},{"id":"1234"}]`)
+	})
+
+	opt := &PipelineListOptions{ListOptions: ListOptions{Page: 2, PerPage: 2}}
+	pipelines, resp, err := client.Pipelines.List("my-great-org", opt)
+	if err != nil {
+		t.Errorf("Pipelines.List returned error: %v", err)
+	}
+
+	want := []Pipeline{{ID: String("123")}, {ID: String("1234")}}
+	if !reflect.DeepEqual(pipelines, want) {
+		t.Errorf("Pipelines.List returned %+v, want %+v", pipelines, want)
+	}
+
+	if got, want := resp.NextPage, 3; got != want {
+		t.Errorf("Pipelines.List NextPage is %v, want %v", got, want)
+	}
+}
+
 func TestPipelinesService_Create(t *testing.T) {
 	setup()
 	defer teardown()
@@ -79,6 +109,55 @@ func TestPipelinesService_Create(t *testing.T) {
 
 }
 
+func TestPipelinesService_Create_configuration(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &CreatePipeline{Name: "my-great-pipeline",
+		Repository:    "my-great-repo",
+		Configuration: "steps:\n  - command: \"script/release.sh\"\n",
+		DefaultBranch: "main",
+		Tags:          []string{"deploy"},
+	}
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		v := new(CreatePipeline)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		if !reflect.DeepEqual(v, input) {
+			t.Errorf("Request body = %+v, want %+v", v, input)
+		}
+
+		fmt.Fprint(w, `{"name":"my-great-pipeline","repository":"my-great-repo"}`)
+	})
+
+	pipeline, _, err := client.Pipelines.Create("my-great-org", input)
+	if err != nil {
+		t.Errorf("Pipelines.Create returned error: %v", err)
+	}
+
+	want := &Pipeline{Name: String("my-great-pipeline"), Repository: String("my-great-repo")}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Create returned %+v, want %+v", pipeline, want)
+	}
+}
+
+func TestPipelinesService_Create_noStepsOrConfiguration(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not have been sent")
+	})
+
+	_, _, err := client.Pipelines.Create("my-great-org", &CreatePipeline{Name: "my-great-pipeline", Repository: "my-great-repo"})
+	if err == nil {
+		t.Error("Pipelines.Create returned no error, want an error")
+	}
+}
+
 func TestPipelinesService_Get(t *testing.T) {
 	setup()
 	defer teardown()
@@ -105,95 +184,272 @@ func TestPipelinesService_Get(t *testing.T) {
 	}
 }
 
+func TestPipelinesService_GetBadge(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"id":"123","badge_url":"%s/3a54bf7b.svg"}`, server.URL)
+	})
+
+	mux.HandleFunc("/3a54bf7b.svg", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"branch": "release/1.0"})
+		w.Header().Set("Content-Type", "image/svg+xml")
+		fmt.Fprint(w, `<svg>passing</svg>`)
+	})
+
+	badge, _, err := client.Pipelines.GetBadge("my-great-org", "my-great-pipeline-slug", "release/1.0")
+	if err != nil {
+		t.Errorf("Pipelines.GetBadge returned error: %v", err)
+	}
+
+	if got, want := string(badge), `<svg>passing</svg>`; got != want {
+		t.Errorf("Pipelines.GetBadge returned %q, want %q", got, want)
+	}
+}
+
+func TestPipelinesService_Get_full(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123",
+						"url":"https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug",
+						"web_url":"https://buildkite.com/my-great-org/my-great-pipeline-slug",
+						"name":"My Great Pipeline",
+						"slug":"my-great-pipeline-slug",
+						"repository":"git@github.com:my-great-org/my-great-repo.git",
+						"builds_url":"https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug/builds",
+						"badge_url":"https://badge.buildkite.com/123.svg",
+						"created_at":"2015-03-04T02:26:54Z",
+						"default_branch":"main",
+						"scheduled_builds_count":1,
+						"running_builds_count":2,
+						"scheduled_jobs_count":3,
+						"running_jobs_count":4,
+						"waiting_jobs_count":5,
+						"steps":[{"type":"script","command":"make"}]}`)
+	})
+
+	pipeline, _, err := client.Pipelines.Get("my-great-org", "my-great-pipeline-slug")
+	if err != nil {
+		t.Errorf("Pipelines.Get returned error: %v", err)
+	}
+
+	want := &Pipeline{
+		ID:                   String("123"),
+		URL:                  String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug"),
+		WebURL:               String("https://buildkite.com/my-great-org/my-great-pipeline-slug"),
+		Name:                 String("My Great Pipeline"),
+		Slug:                 String("my-great-pipeline-slug"),
+		Repository:           String("git@github.com:my-great-org/my-great-repo.git"),
+		BuildsURL:            String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug/builds"),
+		BadgeURL:             String("https://badge.buildkite.com/123.svg"),
+		CreatedAt:            NewTimestamp(referenceTime),
+		DefaultBranch:        String("main"),
+		ScheduledBuildsCount: Int(1),
+		RunningBuildsCount:   Int(2),
+		ScheduledJobsCount:   Int(3),
+		RunningJobsCount:     Int(4),
+		WaitingJobsCount:     Int(5),
+		Steps:                []*Step{{Type: String("script"), Command: String("make")}},
+	}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Get returned %+v, want %+v", pipeline, want)
+	}
+}
+
 func TestPipelinesService_Delete(t *testing.T) {
 	setup()
 	defer teardown()
 
 	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
 	})
 
-	_, err := client.Pipelines.Delete("my-great-org", "my-great-pipeline-slug")
+	resp, err := client.Pipelines.Delete("my-great-org", "my-great-pipeline-slug")
 	if err != nil {
 		t.Errorf("Pipelines.Delete returned error: %v", err)
 	}
+
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Errorf("Pipelines.Delete returned status %d, want %d", got, want)
+	}
 }
 
-func TestPipelinesService_Update(t *testing.T) {
+func TestPipelinesService_Update_visibility(t *testing.T) {
 	setup()
 	defer teardown()
 
-	input := &CreatePipeline{Name: *String("my-great-pipeline"),
-		Repository: *String("my-great-repo"),
-		Steps: []Step{Step{Type: String("script"),
-			Name:    String("Build :package"),
-			Command: String("script/release.sh")}},
-	}
-
-	mux.HandleFunc("/v2/organizations/my-great-org/pipelines", func(w http.ResponseWriter, r *http.Request) {
-		v := new(CreatePipeline)
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
 		json.NewDecoder(r.Body).Decode(&v)
 
-		testMethod(t, r, "POST")
+		testMethod(t, r, "PATCH")
 
-		if !reflect.DeepEqual(v, input) {
-			t.Errorf("Request body = %+v, want %+v", v, input)
+		want := map[string]interface{}{"visibility": "public"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
 		}
 
-		fmt.Fprint(w, `{
-						"name":"my-great-pipeline",
-						"repository":"my-great-repo",
-						"steps": [
-							{
-								"type": "script",
-								"name": "Build :package:",
-								"command": "script/release.sh"
-							}
-						],
-						"slug": "my-great-repo"
-					}`)
+		fmt.Fprint(w, `{"slug":"my-great-pipeline-slug","visibility":"public"}`)
 	})
 
-	pipeline, _, err := client.Pipelines.Create("my-great-org", input)
+	pipeline, _, err := client.Pipelines.Update("my-great-org", "my-great-pipeline-slug", &UpdatePipeline{Visibility: String(PipelineVisibilityPublic)})
 	if err != nil {
-		t.Errorf("Pipelines.Create returned error: %v", err)
+		t.Errorf("Pipelines.Update returned error: %v", err)
 	}
 
-	pipeline.Name = String("derp")
+	want := &Pipeline{Slug: String("my-great-pipeline-slug"), Visibility: String("public")}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Update returned %+v, want %+v", pipeline, want)
+	}
 
-	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-repo", func(w http.ResponseWriter, r *http.Request) {
-		v := new(CreatePipeline)
+	if _, _, err := client.Pipelines.Update("my-great-org", "my-great-pipeline-slug", &UpdatePipeline{Visibility: String("secret")}); err == nil {
+		t.Error("Pipelines.Update returned no error for an invalid visibility")
+	}
+}
+
+func TestPipelinesService_Update(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
 		json.NewDecoder(r.Body).Decode(&v)
 
 		testMethod(t, r, "PATCH")
 
+		want := map[string]interface{}{"description": "my great description"}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
 		fmt.Fprint(w, `{
-						"name":"derp",
+						"name":"my-great-pipeline",
 						"repository":"my-great-repo",
+						"slug": "my-great-pipeline-slug",
 						"steps": [
 							{
 								"type": "script",
 								"name": "Build :package:",
 								"command": "script/release.sh"
 							}
-						],
-						"slug": "my-great-repo"
+						]
 					}`)
 	})
 
-	_, err = client.Pipelines.Update("my-great-org", pipeline)
+	input := &UpdatePipeline{Description: String("my great description")}
+	pipeline, _, err := client.Pipelines.Update("my-great-org", "my-great-pipeline-slug", input)
 	if err != nil {
 		t.Errorf("Pipelines.Update returned error: %v", err)
 	}
 
-	want := &Pipeline{Name: String("derp"),
+	want := &Pipeline{Name: String("my-great-pipeline"),
 		Repository: String("my-great-repo"),
+		Slug:       String("my-great-pipeline-slug"),
 		Steps: []*Step{&Step{Type: String("script"),
 			Name:    String("Build :package:"),
 			Command: String("script/release.sh")}},
-		Slug: String("my-great-repo"),
 	}
 	if !reflect.DeepEqual(pipeline, want) {
 		t.Errorf("Pipelines.Update returned %+v, want %+v", pipeline, want)
 	}
 }
+
+func TestPipelinesService_UploadSteps(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "PATCH")
+
+		want := map[string]interface{}{
+			"configuration": `{"steps":[{"command":"make test"},"wait",{"block":":rocket: Release"},{"trigger":"deploy"}]}`,
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
+		fmt.Fprint(w, `{"slug":"my-great-pipeline-slug"}`)
+	})
+
+	steps := PipelineSteps{
+		&CommandStep{Command: "make test"},
+		&WaitStep{},
+		&BlockStep{Block: ":rocket: Release"},
+		&TriggerStep{Trigger: "deploy"},
+	}
+	pipeline, _, err := client.Pipelines.UploadSteps("my-great-org", "my-great-pipeline-slug", steps)
+	if err != nil {
+		t.Errorf("Pipelines.UploadSteps returned error: %v", err)
+	}
+
+	want := &Pipeline{Slug: String("my-great-pipeline-slug")}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.UploadSteps returned %+v, want %+v", pipeline, want)
+	}
+}
+
+func TestPipelinesService_Update_stepsAndConfiguration(t *testing.T) {
+	setup()
+	defer teardown()
+
+	input := &UpdatePipeline{
+		Configuration: String("steps: []"),
+		Steps:         PipelineSteps{&WaitStep{}},
+	}
+	if _, _, err := client.Pipelines.Update("my-great-org", "my-great-pipeline-slug", input); err == nil {
+		t.Error("Expected error when both Configuration and Steps are set")
+	}
+	if _, _, err := client.Pipelines.UploadSteps("my-great-org", "my-great-pipeline-slug", nil); err == nil {
+		t.Error("Expected error for empty steps")
+	}
+}
+
+func TestPipelinesService_Archive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug/archive", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"slug":"my-great-pipeline-slug","archived":true}`)
+	})
+
+	pipeline, _, err := client.Pipelines.Archive("my-great-org", "my-great-pipeline-slug")
+	if err != nil {
+		t.Errorf("Pipelines.Archive returned error: %v", err)
+	}
+
+	want := &Pipeline{Slug: String("my-great-pipeline-slug"), Archived: Bool(true)}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Archive returned %+v, want %+v", pipeline, want)
+	}
+}
+
+func TestPipelinesService_Unarchive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/my-great-pipeline-slug/unarchive", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprint(w, `{"slug":"my-great-pipeline-slug","archived":false}`)
+	})
+
+	pipeline, _, err := client.Pipelines.Unarchive("my-great-org", "my-great-pipeline-slug")
+	if err != nil {
+		t.Errorf("Pipelines.Unarchive returned error: %v", err)
+	}
+
+	want := &Pipeline{Slug: String("my-great-pipeline-slug"), Archived: Bool(false)}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("Pipelines.Unarchive returned %+v, want %+v", pipeline, want)
+	}
+}
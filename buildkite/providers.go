@@ -58,6 +58,12 @@ type BitbucketSettings struct {
 	BuildTags                               *bool   `json:"build_tags,omitempty"`
 	PublishCommitStatus                     *bool   `json:"publish_commit_status,omitempty"`
 	PublishCommitStatusPerStep              *bool   `json:"publish_commit_status_per_step,omitempty"`
+	BuildBranches                           *bool   `json:"build_branches,omitempty"`
+
+	// FilterEnabled turns on FilterCondition, a conditional expression which
+	// webhooks must match to create a build.
+	FilterEnabled   *bool   `json:"filter_enabled,omitempty"`
+	FilterCondition *string `json:"filter_condition,omitempty"`
 
 	// Read-only
 	Repository *string `json:"repository,omitempty"`
@@ -77,6 +83,14 @@ type GitHubSettings struct {
 	BuildTags                               *bool   `json:"build_tags,omitempty"`
 	PublishCommitStatus                     *bool   `json:"publish_commit_status,omitempty"`
 	PublishCommitStatusPerStep              *bool   `json:"publish_commit_status_per_step,omitempty"`
+	SeparatePullRequestStatuses             *bool   `json:"separate_pull_request_statuses,omitempty"`
+	PublishBlockedAsPending                 *bool   `json:"publish_blocked_as_pending,omitempty"`
+	BuildBranches                           *bool   `json:"build_branches,omitempty"`
+
+	// FilterEnabled turns on FilterCondition, a conditional expression which
+	// webhooks must match to create a build.
+	FilterEnabled   *bool   `json:"filter_enabled,omitempty"`
+	FilterCondition *string `json:"filter_condition,omitempty"`
 
 	// Read-only
 	Repository *string `json:"repository,omitempty"`
@@ -86,6 +100,20 @@ func (s *GitHubSettings) isProviderSettings() {}
 
 // GitLabSettings are settings for pipelines building from GitLab repositories.
 type GitLabSettings struct {
+	TriggerMode                             *string `json:"trigger_mode,omitempty"`
+	BuildPullRequests                       *bool   `json:"build_pull_requests,omitempty"`
+	PullRequestBranchFilterEnabled          *bool   `json:"pull_request_branch_filter_enabled,omitempty"`
+	PullRequestBranchFilterConfiguration    *string `json:"pull_request_branch_filter_configuration,omitempty"`
+	SkipPullRequestBuildsForExistingCommits *bool   `json:"skip_pull_request_builds_for_existing_commits,omitempty"`
+	BuildTags                               *bool   `json:"build_tags,omitempty"`
+	PublishCommitStatus                     *bool   `json:"publish_commit_status,omitempty"`
+	BuildBranches                           *bool   `json:"build_branches,omitempty"`
+
+	// FilterEnabled turns on FilterCondition, a conditional expression which
+	// webhooks must match to create a build.
+	FilterEnabled   *bool   `json:"filter_enabled,omitempty"`
+	FilterCondition *string `json:"filter_condition,omitempty"`
+
 	// Read-only
 	Repository *string `json:"repository,omitempty"`
 }
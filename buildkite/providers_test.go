@@ -73,3 +73,86 @@ func TestUnmarshalUnknownProvider(t *testing.T) {
 		t.Errorf("Failed to unmarshal unknown provider: got %+v, want %+v", provider, want)
 	}
 }
+
+func TestMarshalGitHubSettings(t *testing.T) {
+	p := CreatePipeline{
+		Name:       "Internal",
+		Repository: "git@github.com:my-great-org/internal.git",
+		ProviderSettings: &GitHubSettings{
+			TriggerMode:       String("code"),
+			BuildPullRequests: Bool(false),
+			FilterEnabled:     Bool(true),
+			FilterCondition:   String(`build.branch == "main"`),
+		},
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Error marshalling pipeline: %v", err)
+	}
+
+	var got struct {
+		ProviderSettings map[string]interface{} `json:"provider_settings"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Error unmarshalling pipeline: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"trigger_mode":        "code",
+		"build_pull_requests": false,
+		"filter_enabled":      true,
+		"filter_condition":    `build.branch == "main"`,
+	}
+	if !reflect.DeepEqual(got.ProviderSettings, want) {
+		t.Errorf("Marshalled provider settings %+v, want %+v", got.ProviderSettings, want)
+	}
+}
+
+func TestUnmarshalGitHubProviderSettings(t *testing.T) {
+	var provider Provider
+	err := json.Unmarshal([]byte(`{"id": "github", "settings": {"trigger_mode": "code", "build_pull_requests": false, "build_tags": true, "publish_commit_status": true, "filter_enabled": false}}`), &provider)
+	if err != nil {
+		t.Errorf("Error unmarshalling GitHub provider: %v", err)
+	}
+
+	want := Provider{
+		ID: "github",
+		Settings: &GitHubSettings{
+			TriggerMode:         String("code"),
+			BuildPullRequests:   Bool(false),
+			BuildTags:           Bool(true),
+			PublishCommitStatus: Bool(true),
+			FilterEnabled:       Bool(false),
+		},
+	}
+
+	if !reflect.DeepEqual(provider, want) {
+		t.Errorf("Failed to unmarshal GitHub provider: got %+v, want %+v", provider, want)
+	}
+}
+
+func TestUnmarshalGitLabProviderSettings(t *testing.T) {
+	var provider Provider
+	err := json.Unmarshal([]byte(`{"id": "gitlab", "settings": {"trigger_mode": "code", "build_pull_requests": true, "build_branches": true, "publish_commit_status": false, "filter_enabled": true, "filter_condition": "build.branch == \"main\"", "repository": "my-great-org/my-pipeline"}}`), &provider)
+	if err != nil {
+		t.Errorf("Error unmarshalling GitLab provider: %v", err)
+	}
+
+	want := Provider{
+		ID: "gitlab",
+		Settings: &GitLabSettings{
+			TriggerMode:         String("code"),
+			BuildPullRequests:   Bool(true),
+			BuildBranches:       Bool(true),
+			PublishCommitStatus: Bool(false),
+			FilterEnabled:       Bool(true),
+			FilterCondition:     String(`build.branch == "main"`),
+			Repository:          String("my-great-org/my-pipeline"),
+		},
+	}
+
+	if !reflect.DeepEqual(provider, want) {
+		t.Errorf("Failed to unmarshal GitLab provider: got %+v, want %+v", provider, want)
+	}
+}
@@ -0,0 +1,87 @@
+package buildkite
+
+import (
+	"testing"
+)
+
+func TestStringify(t *testing.T) {
+	var nilPointer *string
+
+	var tests = []struct {
+		in  interface{}
+		out string
+	}{
+		// basic types
+		{"foo", `"foo"`},
+		{123, `123`},
+		{1.5, `1.5`},
+		{false, `false`},
+		{
+			[]string{"a", "b"},
+			`["a" "b"]`,
+		},
+		{
+			struct {
+				A []string
+			}{nil},
+			// nil slice is skipped
+			`{}`,
+		},
+
+		// pointers
+		{nilPointer, `<nil>`},
+		{String("foo"), `"foo"`},
+		{Int(123), `123`},
+		{Bool(false), `false`},
+
+		// actual buildkite structs
+		{
+			Timestamp{referenceTime},
+			`buildkite.Timestamp{2015-03-04 02:26:54 +0000 UTC}`,
+		},
+		{
+			&Timestamp{referenceTime},
+			`buildkite.Timestamp{2015-03-04 02:26:54 +0000 UTC}`,
+		},
+		{
+			Build{Number: Int(42), State: String("passed")},
+			`buildkite.Build{Number:42, State:"passed"}`,
+		},
+		{
+			Pipeline{Slug: String("sup-keith"), Provider: &Provider{ID: "github", Settings: &GitHubSettings{BuildTags: Bool(true)}}},
+			`buildkite.Pipeline{Slug:"sup-keith", Provider:buildkite.Provider{ID:"github", Settings:buildkite.GitHubSettings{BuildTags:true}}}`,
+		},
+	}
+
+	for i, tt := range tests {
+		s := Stringify(tt.in)
+		if s != tt.out {
+			t.Errorf("%d. Stringify(%q) => %q, want %q", i, tt.in, s, tt.out)
+		}
+	}
+}
+
+// Directly test the String() methods on various types. We don't do an
+// exaustive test of all the various field types, since TestStringify() above
+// takes care of that. Rather, we just make sure that Stringify() is being
+// used to build the strings, which we do by verifying that pointers are
+// stringified as their underlying value.
+func TestString(t *testing.T) {
+	var tests = []struct {
+		in  interface{}
+		out string
+	}{
+		{Build{ID: String("123")}, `buildkite.Build{ID:"123"}`},
+		{Job{ID: String("123")}, `buildkite.Job{ID:"123", Agent:buildkite.Agent{}, WebURL:""}`},
+		{Pipeline{ID: String("123")}, `buildkite.Pipeline{ID:"123"}`},
+	}
+
+	for i, tt := range tests {
+		s := tt.in.(interface {
+			String() string
+		}).String()
+		if s != tt.out {
+			t.Errorf("%d. String() => %q, want %q", i, s, tt.out)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import "fmt"
+
+// The access levels a team can have to a pipeline.
+const (
+	TeamPipelineAccessReadOnly           = "read_only"
+	TeamPipelineAccessBuildAndRead       = "build_and_read"
+	TeamPipelineAccessManageBuildAndRead = "manage_build_and_read"
+)
+
+// TeamsService handles communication with the teams related
+// methods of the buildkite API.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/teams
+type TeamsService struct {
+	client *Client
+}
+
+// Team represents a buildkite team.
+type Team struct {
+	ID          *string    `json:"id,omitempty"`
+	Name        *string    `json:"name,omitempty"`
+	Slug        *string    `json:"slug,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Privacy     *string    `json:"privacy,omitempty"`
+	Default     *bool      `json:"default,omitempty"`
+	MembersURL  *string    `json:"members_url,omitempty"`
+	CreatedAt   *Timestamp `json:"created_at,omitempty"`
+	CreatedBy   *User      `json:"created_by,omitempty"`
+}
+
+// TeamMember represents the membership of a user in a team.
+type TeamMember struct {
+	UserID    *string    `json:"user_id,omitempty"`
+	UserName  *string    `json:"user_name,omitempty"`
+	Role      *string    `json:"role,omitempty"`
+	CreatedAt *Timestamp `json:"created_at,omitempty"`
+}
+
+// TeamPipeline represents the access a team has to a pipeline.
+type TeamPipeline struct {
+	AccessLevel *string    `json:"access_level,omitempty"`
+	PipelineID  *string    `json:"pipeline_id,omitempty"`
+	PipelineURL *string    `json:"pipeline_url,omitempty"`
+	CreatedAt   *Timestamp `json:"created_at,omitempty"`
+}
+
+// TeamListOptions specifies the optional parameters to the
+// TeamsService.List method.
+type TeamListOptions struct {
+
+	// Filters the results to the teams the given user is a member of
+	UserID string `url:"user_id,omitempty"`
+
+	ListOptions
+}
+
+// TeamMemberListOptions specifies the optional parameters to the
+// TeamsService.ListMembers method.
+type TeamMemberListOptions struct {
+	ListOptions
+}
+
+// List the teams for a given organisation.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/teams#list-teams
+func (ts *TeamsService) List(org string, opt *TeamListOptions) ([]Team, *Response, error) {
+	var u string
+
+	u = fmt.Sprintf("v2/organizations/%s/teams", org)
+
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ts.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teams := new([]Team)
+	resp, err := ts.client.Do(req, teams)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *teams, resp, err
+}
+
+// ListMembers lists the members of a team.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/teams/members#list-team-members
+func (ts *TeamsService) ListMembers(org string, teamID string, opt *TeamMemberListOptions) ([]TeamMember, *Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/teams/%s/members", org, teamID)
+
+	u, err := addOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ts.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members := new([]TeamMember)
+	resp, err := ts.client.Do(req, members)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return *members, resp, err
+}
+
+// AddPipeline gives a team access to a pipeline. The access level must be one
+// of read_only, build_and_read or manage_build_and_read.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/teams/pipelines#create-a-team-pipeline
+func (ts *TeamsService) AddPipeline(org string, teamID string, pipelineID string, accessLevel string) (*TeamPipeline, *Response, error) {
+	switch accessLevel {
+	case TeamPipelineAccessReadOnly, TeamPipelineAccessBuildAndRead, TeamPipelineAccessManageBuildAndRead:
+	default:
+		return nil, nil, fmt.Errorf("invalid access level %q", accessLevel)
+	}
+
+	u := fmt.Sprintf("v2/organizations/%s/teams/%s/pipelines", org, teamID)
+
+	body := &TeamPipeline{PipelineID: &pipelineID, AccessLevel: &accessLevel}
+	req, err := ts.client.NewRequest("POST", u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tp := new(TeamPipeline)
+	resp, err := ts.client.Do(req, tp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return tp, resp, err
+}
+
+// RemovePipeline removes the access a team has to a pipeline.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/teams/pipelines#delete-a-team-pipeline
+func (ts *TeamsService) RemovePipeline(org string, teamID string, pipelineID string) (*Response, error) {
+
+	u := fmt.Sprintf("v2/organizations/%s/teams/%s/pipelines/%s", org, teamID, pipelineID)
+
+	req, err := ts.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.client.Do(req, nil)
+}
@@ -0,0 +1,111 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestTeamsService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/teams", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{
+			"user_id": "7da07e25-0383-4aff-a7cf-14d1a9aa098f",
+		})
+		fmt.Fprint(w, `[{"id":"123","name":"Production","slug":"production","privacy":"secret","default":false}]`)
+	})
+
+	opt := &TeamListOptions{UserID: "7da07e25-0383-4aff-a7cf-14d1a9aa098f"}
+	teams, _, err := client.Teams.List("my-great-org", opt)
+	if err != nil {
+		t.Errorf("Teams.List returned error: %v", err)
+	}
+
+	want := []Team{{ID: String("123"), Name: String("Production"), Slug: String("production"), Privacy: String("secret"), Default: Bool(false)}}
+	if !reflect.DeepEqual(teams, want) {
+		t.Errorf("Teams.List returned %+v, want %+v", teams, want)
+	}
+}
+
+func TestTeamsService_ListMembers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/teams/123/members", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testFormValues(t, r, values{"page": "2", "per_page": "50"})
+		fmt.Fprint(w, `[{"user_id":"456","user_name":"Jane Doe","role":"maintainer"}]`)
+	})
+
+	members, _, err := client.Teams.ListMembers("my-great-org", "123", &TeamMemberListOptions{ListOptions{Page: 2, PerPage: 50}})
+	if err != nil {
+		t.Errorf("Teams.ListMembers returned error: %v", err)
+	}
+
+	want := []TeamMember{{UserID: String("456"), UserName: String("Jane Doe"), Role: String("maintainer")}}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("Teams.ListMembers returned %+v, want %+v", members, want)
+	}
+}
+
+func TestTeamsService_AddPipeline(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/teams/123/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		v := new(TeamPipeline)
+		json.NewDecoder(r.Body).Decode(&v)
+
+		testMethod(t, r, "POST")
+
+		want := &TeamPipeline{PipelineID: String("456"), AccessLevel: String("read_only")}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("Request body = %+v, want %+v", v, want)
+		}
+
+		fmt.Fprint(w, `{"pipeline_id":"456","access_level":"read_only"}`)
+	})
+
+	tp, _, err := client.Teams.AddPipeline("my-great-org", "123", "456", TeamPipelineAccessReadOnly)
+	if err != nil {
+		t.Errorf("Teams.AddPipeline returned error: %v", err)
+	}
+
+	want := &TeamPipeline{PipelineID: String("456"), AccessLevel: String("read_only")}
+	if !reflect.DeepEqual(tp, want) {
+		t.Errorf("Teams.AddPipeline returned %+v, want %+v", tp, want)
+	}
+}
+
+func TestTeamsService_AddPipeline_invalidAccessLevel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/teams/123/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not have been sent")
+	})
+
+	if _, _, err := client.Teams.AddPipeline("my-great-org", "123", "456", "admin"); err == nil {
+		t.Error("Teams.AddPipeline returned no error for an invalid access level")
+	}
+}
+
+func TestTeamsService_RemovePipeline(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/teams/123/pipelines/456", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.Teams.RemovePipeline("my-great-org", "123", "456")
+	if err != nil {
+		t.Errorf("Teams.RemovePipeline returned error: %v", err)
+	}
+}
@@ -5,7 +5,12 @@
 
 package buildkite
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // BuildKiteDateFormat is the format of the dates used throughout the
 // api, note this odd string is used to parse/format dates in go
@@ -33,12 +38,44 @@ func (ts Timestamp) MarshalJSON() ([]byte, error) {
 	return []byte(ts.Format(`"` + BuildKiteDateFormat + `"`)), nil
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
-func (ts *Timestamp) UnmarshalJSON(data []byte) (err error) {
-	(*ts).Time, err = time.Parse(`"`+BuildKiteDateFormat+`"`, string(data))
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// RFC 3339 strings with or without fractional seconds, the webhook event
+// format, and Unix epoch seconds as sent by older webhooks.
+func (ts *Timestamp) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if str == "null" {
+		return nil
+	}
+
+	if !strings.HasPrefix(str, `"`) {
+		secs, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %s", str)
+		}
+		(*ts).Time = time.Unix(secs, 0).UTC()
+		return nil
+	}
+
+	unquoted, err := strconv.Unquote(str)
+	if err != nil {
+		return err
+	}
+	return ts.UnmarshalText([]byte(unquoted))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, formatting the
+// timestamp as RFC 3339.
+func (ts Timestamp) MarshalText() ([]byte, error) {
+	return []byte(ts.Format(BuildKiteDateFormat)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts
+// RFC 3339, with or without fractional seconds, and the webhook event format.
+func (ts *Timestamp) UnmarshalText(data []byte) (err error) {
+	(*ts).Time, err = time.Parse(BuildKiteDateFormat, string(data))
 	if err != nil {
 		// try the webhook format too; avoid clobbering the error if both fail
-		t, err2 := time.Parse(`"`+BuildKiteEventDateFormat+`"`, string(data))
+		t, err2 := time.Parse(BuildKiteEventDateFormat, string(data))
 		if err2 == nil {
 			(*ts).Time = t
 			err = err2
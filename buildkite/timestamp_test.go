@@ -50,6 +50,13 @@ func TestTimestamp_Unmarshal(t *testing.T) {
 		{"Reference", referenceTimeStr, Timestamp{referenceTime}, false, true},
 		{"Mismatch", referenceTimeStr, Timestamp{}, false, false},
 		{"Invalid", `"asdf"`, Timestamp{referenceTime}, true, false},
+		{"Fractional", `"2015-03-04T02:26:54.000Z"`, Timestamp{referenceTime}, false, true},
+		{"Nanoseconds", `"2015-03-04T02:26:54.123456789Z"`, Timestamp{referenceTime.Add(123456789)}, false, true},
+		{"Offset", `"2015-03-04T12:26:54+10:00"`, Timestamp{referenceTime}, false, true},
+		{"Event", `"2015-03-04 02:26:54 UTC"`, Timestamp{referenceTime}, false, true},
+		{"Epoch", `1425436014`, Timestamp{referenceTime}, false, true},
+		{"InvalidNumber", `1425436014.5`, Timestamp{referenceTime}, true, false},
+		{"Null", `null`, Timestamp{}, false, true},
 	}
 	for _, tc := range testCases {
 		var got Timestamp
@@ -64,3 +71,54 @@ func TestTimestamp_Unmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestTimestamp_MarshalText(t *testing.T) {
+	got, err := Timestamp{referenceTime}.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText returned error: %v", err)
+	}
+	if want := "2015-03-04T02:26:54Z"; string(got) != want {
+		t.Errorf("MarshalText returned %q, want %q", got, want)
+	}
+}
+
+func TestTimestamp_UnmarshalText(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		data    string
+		want    Timestamp
+		wantErr bool
+	}{
+		{"RFC3339", "2015-03-04T02:26:54Z", Timestamp{referenceTime}, false},
+		{"Event", "2015-03-04 02:26:54 UTC", Timestamp{referenceTime}, false},
+		{"Invalid", "asdf", Timestamp{}, true},
+	}
+	for _, tc := range testCases {
+		var got Timestamp
+		err := got.UnmarshalText([]byte(tc.data))
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%s: gotErr=%v, wantErr=%v, err=%v", tc.desc, gotErr, tc.wantErr, err)
+			continue
+		}
+		if !tc.wantErr && !got.Equal(tc.want) {
+			t.Errorf("%s: got=%#v, want=%#v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestTimestamp_TextRoundTrip(t *testing.T) {
+	in := Timestamp{time.Date(2015, time.March, 4, 2, 26, 54, 123456789, time.UTC)}
+
+	text, err := in.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText returned error: %v", err)
+	}
+
+	var out Timestamp
+	if err := out.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText returned error: %v", err)
+	}
+	if !out.Equal(in) {
+		t.Errorf("round trip returned %v, want %v", out, in)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Tracer starts spans around API calls, so the client can be plugged into a
+// tracing system such as OpenTelemetry without depending on it.
+//
+// StartSpan is passed the request's context and returns the context the
+// request is sent with, which carries the new span.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced API call, started by a Tracer.
+//
+// Do sets the attributes http.method, http.url and http.status_code, and
+// records the error, if any, before ending the span.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// spanActions are the path segments which name an action on the resource
+// before them, e.g. builds/1/cancel.
+var spanActions = map[string]bool{
+	"archive":         true,
+	"cancel":          true,
+	"download":        true,
+	"env":             true,
+	"log":             true,
+	"pause_dispatch":  true,
+	"rebuild":         true,
+	"resume_dispatch": true,
+	"retry":           true,
+	"stop":            true,
+	"unarchive":       true,
+	"unblock":         true,
+}
+
+// spanName names the span for req after the resource and action it
+// addresses, e.g. buildkite.builds.list for GET v2/organizations/o/builds.
+func spanName(req *http.Request) string {
+	segs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segs) > 0 && segs[0] == "v2" {
+		segs = segs[1:]
+	}
+	if len(segs) == 0 || segs[0] == "" {
+		return "buildkite." + strings.ToLower(req.Method)
+	}
+
+	var resource, action string
+	last := segs[len(segs)-1]
+
+	switch {
+	case spanActions[last] && len(segs) >= 3:
+		// e.g. builds/{number}/cancel
+		resource, action = segs[len(segs)-3], last
+	case len(segs)%2 == 1:
+		// a collection, e.g. pipelines/{slug}/builds, or a singleton like user
+		resource = last
+		switch req.Method {
+		case "GET":
+			action = "list"
+			if !strings.HasSuffix(resource, "s") {
+				action = "get"
+			}
+		case "POST":
+			action = "create"
+		default:
+			action = strings.ToLower(req.Method)
+		}
+	default:
+		// a single item, e.g. builds/{number}
+		resource = segs[len(segs)-2]
+		switch req.Method {
+		case "GET":
+			action = "get"
+		case "PUT", "PATCH":
+			action = "update"
+		default:
+			action = strings.ToLower(req.Method)
+		}
+	}
+
+	return "buildkite." + strings.Replace(resource, "-", "_", -1) + "." + action
+}
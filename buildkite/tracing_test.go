@@ -0,0 +1,118 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *testSpan) RecordError(err error)                      { s.err = err }
+func (s *testSpan) End()                                       { s.ended = true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &testSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestDo_tracer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"123"}]`)
+	})
+
+	tracer := &testTracer{}
+	client.Tracer = tracer
+
+	_, _, err := client.Builds.ListByPipeline("my-great-org", "sup-keith", nil)
+	if err != nil {
+		t.Fatalf("Builds.ListByPipeline returned error: %v", err)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Tracer started %d spans, want 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+
+	if got, want := span.name, "buildkite.builds.list"; got != want {
+		t.Errorf("span name is %q, want %q", got, want)
+	}
+	want := map[string]interface{}{
+		"http.method":      "GET",
+		"http.url":         server.URL + "/v2/organizations/my-great-org/pipelines/sup-keith/builds",
+		"http.status_code": http.StatusOK,
+	}
+	if !reflect.DeepEqual(span.attributes, want) {
+		t.Errorf("span attributes are %+v, want %+v", span.attributes, want)
+	}
+	if span.err != nil {
+		t.Errorf("span recorded error %v", span.err)
+	}
+	if !span.ended {
+		t.Error("span was not ended")
+	}
+}
+
+func TestDo_tracerError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	tracer := &testTracer{}
+	client.Tracer = tracer
+
+	_, _, err := client.Builds.Get("my-great-org", "sup-keith", "1")
+	if err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+
+	span := tracer.spans[0]
+	if got, want := span.attributes["http.status_code"], http.StatusNotFound; got != want {
+		t.Errorf("span http.status_code is %v, want %v", got, want)
+	}
+	if span.err != err {
+		t.Errorf("span recorded error %v, want %v", span.err, err)
+	}
+}
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/v2/builds", "buildkite.builds.list"},
+		{"GET", "/v2/organizations/my-great-org/pipelines/sup-keith/builds/1", "buildkite.builds.get"},
+		{"POST", "/v2/organizations/my-great-org/pipelines/sup-keith/builds", "buildkite.builds.create"},
+		{"PUT", "/v2/organizations/my-great-org/pipelines/sup-keith/builds/1/cancel", "buildkite.builds.cancel"},
+		{"PATCH", "/v2/organizations/my-great-org/pipelines/sup-keith", "buildkite.pipelines.update"},
+		{"DELETE", "/v2/organizations/my-great-org/agents/123", "buildkite.agents.delete"},
+		{"GET", "/v2/organizations/my-great-org", "buildkite.organizations.get"},
+		{"GET", "/v2/user", "buildkite.user.get"},
+		{"DELETE", "/v2/access-token", "buildkite.access_token.delete"},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "https://api.buildkite.com"+tt.path, nil)
+		if got := spanName(req); got != tt.want {
+			t.Errorf("spanName(%s %s) is %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -18,15 +18,24 @@ type UserService struct {
 // User represents a buildkite user.
 type User struct {
 	ID        *string    `json:"id,omitempty"`
+	GraphQLID *string    `json:"graphql_id,omitempty"`
 	Name      *string    `json:"name,omitempty"`
 	Email     *string    `json:"email,omitempty"`
+	AvatarURL *string    `json:"avatar_url,omitempty"`
 	CreatedAt *Timestamp `json:"created_at,omitempty"`
 }
 
-// Get the current user.
+// Get the current user, see CurrentUser.
 //
 // buildkite API docs: https://buildkite.com/docs/api
 func (os *UserService) Get() (*User, *Response, error) {
+	return os.CurrentUser()
+}
+
+// CurrentUser gets the user the access token authenticates as.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/rest-api/user
+func (os *UserService) CurrentUser() (*User, *Response, error) {
 	var u string
 
 	u = fmt.Sprintf("v2/user")
@@ -26,3 +26,30 @@ func TestUserService_Get(t *testing.T) {
 		t.Errorf("User.Get returned %+v, want %+v", user, want)
 	}
 }
+
+func TestUserService_CurrentUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/v2/user", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":"123","graphql_id":"VXNlci0tLTEyMw==","name":"Jane Doe","email":"jane@doe.com","avatar_url":"https://www.gravatar.com/avatar/123","created_at":"2015-03-04T02:26:54Z"}`)
+	})
+
+	user, _, err := client.User.CurrentUser()
+	if err != nil {
+		t.Errorf("User.CurrentUser returned error: %v", err)
+	}
+
+	want := &User{
+		ID:        String("123"),
+		GraphQLID: String("VXNlci0tLTEyMw=="),
+		Name:      String("Jane Doe"),
+		Email:     String("jane@doe.com"),
+		AvatarURL: String("https://www.gravatar.com/avatar/123"),
+		CreatedAt: NewTimestamp(referenceTime),
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("User.CurrentUser returned %+v, want %+v", user, want)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2014 Mark Wolfe. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildkite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Webhook event types, as sent in the X-Buildkite-Event header.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/webhooks
+const (
+	EventPing = "ping"
+
+	EventBuildScheduled = "build.scheduled"
+	EventBuildRunning   = "build.running"
+	EventBuildFailing   = "build.failing"
+	EventBuildFinished  = "build.finished"
+
+	EventJobScheduled         = "job.scheduled"
+	EventJobStarted           = "job.started"
+	EventJobFinished          = "job.finished"
+	EventJobActivated         = "job.activated"
+	EventJobTimeLimitExceeded = "job.time_limit_exceeded"
+
+	EventAgentConnected    = "agent.connected"
+	EventAgentLost         = "agent.lost"
+	EventAgentDisconnected = "agent.disconnected"
+	EventAgentStopping     = "agent.stopping"
+	EventAgentStopped      = "agent.stopped"
+)
+
+// WebhookSender represents the user whose action triggered a webhook.
+type WebhookSender struct {
+	ID   *string `json:"id,omitempty"`
+	Name *string `json:"name,omitempty"`
+}
+
+// PingEvent is sent when a webhook notification service is changed.
+type PingEvent struct {
+	Event        *string        `json:"event,omitempty"`
+	Service      *PingService   `json:"service,omitempty"`
+	Organization *Organization  `json:"organization,omitempty"`
+	Sender       *WebhookSender `json:"sender,omitempty"`
+}
+
+// PingService represents the notification service which sent a PingEvent.
+type PingService struct {
+	ID       *string                `json:"id,omitempty"`
+	Provider *string                `json:"provider,omitempty"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// BuildEvent is sent for the build.* webhook events.
+type BuildEvent struct {
+	Event    *string        `json:"event,omitempty"`
+	Build    *Build         `json:"build,omitempty"`
+	Pipeline *Pipeline      `json:"pipeline,omitempty"`
+	Sender   *WebhookSender `json:"sender,omitempty"`
+}
+
+// JobEvent is sent for the job.* webhook events.
+type JobEvent struct {
+	Event    *string        `json:"event,omitempty"`
+	Job      *Job           `json:"job,omitempty"`
+	Build    *Build         `json:"build,omitempty"`
+	Pipeline *Pipeline      `json:"pipeline,omitempty"`
+	Sender   *WebhookSender `json:"sender,omitempty"`
+}
+
+// AgentEvent is sent for the agent.* webhook events.
+type AgentEvent struct {
+	Event  *string        `json:"event,omitempty"`
+	Agent  *Agent         `json:"agent,omitempty"`
+	Sender *WebhookSender `json:"sender,omitempty"`
+}
+
+// ParseWebhook parses the payload of a webhook into one of PingEvent,
+// BuildEvent, JobEvent or AgentEvent, based on eventType, which is the value
+// of the X-Buildkite-Event header. An error is returned for unknown events.
+//
+// The payload is not authenticated; check it with ValidateWebhookSignature
+// before trusting it.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/webhooks
+func ParseWebhook(eventType string, payload []byte) (interface{}, error) {
+	var event interface{}
+
+	switch {
+	case eventType == EventPing:
+		event = &PingEvent{}
+	case strings.HasPrefix(eventType, "build."):
+		event = &BuildEvent{}
+	case strings.HasPrefix(eventType, "job."):
+		event = &JobEvent{}
+	case strings.HasPrefix(eventType, "agent."):
+		event = &AgentEvent{}
+	default:
+		return nil, fmt.Errorf("unknown webhook event type %q", eventType)
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ErrInvalidWebhookSignature is returned by ValidateWebhookSignature when the
+// signature doesn't match the payload.
+var ErrInvalidWebhookSignature = errors.New("buildkite: webhook signature does not match")
+
+// ValidateWebhookSignature checks the X-Buildkite-Signature header of a
+// webhook against its body. The header has the form
+// "timestamp=<unix time>,signature=<hex HMAC>", where the signature is the
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook's secret.
+//
+// A nil error means the payload was signed with secret. The timestamp is not
+// checked for freshness.
+//
+// buildkite API docs: https://buildkite.com/docs/apis/webhooks#webhook-signature
+func ValidateWebhookSignature(header string, body []byte, secret string) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "timestamp":
+			timestamp = kv[1]
+		case "signature":
+			signature = kv[1]
+		}
+	}
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("buildkite: malformed webhook signature header %q", header)
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
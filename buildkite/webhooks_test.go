@@ -0,0 +1,266 @@
+package buildkite
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testBuildWebhookPayload is a build.finished payload in the shape given by
+// the webhook docs, with the build and pipeline as returned by the REST API.
+const testBuildWebhookPayload = `{
+  "event": "build.finished",
+  "build": {
+    "id": "f62a1b4d-10f9-4790-bc1c-e2c3a0c80983",
+    "graphql_id": "QnVpbGQtLS1mNjJhMWI0ZC0xMGY5LTQ3OTAtYmMxYy1lMmMzYTBjODA5ODM=",
+    "url": "https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline/builds/1",
+    "web_url": "https://buildkite.com/my-great-org/my-pipeline/builds/1",
+    "number": 1,
+    "state": "passed",
+    "blocked": false,
+    "message": "Bumping to version 0.2-beta.6",
+    "commit": "abcd0b72a1e580e90712cdd9eb26d3fb41cd09c8",
+    "branch": "main",
+    "tag": null,
+    "env": {},
+    "source": "webhook",
+    "creator": {
+      "id": "3d3c3bf0-7d58-4afe-8fe7-b3017d5504de",
+      "name": "Keith Pitt",
+      "email": "keith@buildkite.com",
+      "avatar_url": "https://www.gravatar.com/avatar/e14f55d3f939977cecbf51b64ff6f861",
+      "created_at": "2015-05-22T12:36:45.309Z"
+    },
+    "created_at": "2015-05-09T21:05:59.874Z",
+    "scheduled_at": "2015-05-09T21:05:59.874Z",
+    "started_at": "2015-05-09T21:05:59.874Z",
+    "finished_at": "2015-05-09T21:05:59.874Z",
+    "meta_data": {},
+    "pull_request": null,
+    "rebuilt_from": null
+  },
+  "pipeline": {
+    "id": "849411f9-9e6d-4739-a0d8-e247088e9b52",
+    "graphql_id": "UGlwZWxpbmUtLS1lOTM4ZGQxZC03MDgwLTQ4ZmQtOGQyMC0yNmQ4ZTRjN2JhN2I=",
+    "url": "https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline",
+    "web_url": "https://buildkite.com/my-great-org/my-pipeline",
+    "name": "My Pipeline",
+    "slug": "my-pipeline",
+    "repository": "git@github.com:my-great-org/my-pipeline",
+    "branch_configuration": null,
+    "default_branch": "main",
+    "provider": {
+      "id": "github",
+      "webhook_url": "https://webhook.buildkite.com/deliver/xxx",
+      "settings": {
+        "publish_commit_status": true,
+        "build_pull_requests": true,
+        "build_pull_request_forks": false,
+        "build_tags": false,
+        "publish_commit_status_per_step": false,
+        "repository": "my-great-org/my-pipeline",
+        "trigger_mode": "code"
+      }
+    },
+    "skip_queued_branch_builds": false,
+    "skip_queued_branch_builds_filter": null,
+    "cancel_running_branch_builds": false,
+    "cancel_running_branch_builds_filter": null,
+    "builds_url": "https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline/builds",
+    "badge_url": "https://badge.buildkite.com/58b3da999635d0ad2daae5f784e56d264343eb02526f129bfb.svg",
+    "created_at": "2015-05-09T21:05:59.874Z",
+    "scheduled_builds_count": 0,
+    "running_builds_count": 0,
+    "scheduled_jobs_count": 0,
+    "running_jobs_count": 0,
+    "waiting_jobs_count": 0
+  },
+  "sender": {
+    "id": "8a7693f8-dbae-4783-9137-84090fce9045",
+    "name": "Keith Pitt"
+  }
+}`
+
+func TestParseWebhook_Build(t *testing.T) {
+	event, err := ParseWebhook(EventBuildFinished, []byte(testBuildWebhookPayload))
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	buildTime := NewTimestamp(time.Date(2015, 5, 9, 21, 5, 59, 874000000, time.UTC))
+	want := &BuildEvent{
+		Event: String("build.finished"),
+		Build: &Build{
+			ID:          String("f62a1b4d-10f9-4790-bc1c-e2c3a0c80983"),
+			URL:         String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline/builds/1"),
+			WebURL:      String("https://buildkite.com/my-great-org/my-pipeline/builds/1"),
+			Number:      Int(1),
+			State:       String("passed"),
+			Blocked:     Bool(false),
+			Message:     String("Bumping to version 0.2-beta.6"),
+			Commit:      String("abcd0b72a1e580e90712cdd9eb26d3fb41cd09c8"),
+			Branch:      String("main"),
+			Env:         BuildEnv{},
+			CreatedAt:   buildTime,
+			ScheduledAt: buildTime,
+			StartedAt:   buildTime,
+			FinishedAt:  buildTime,
+			MetaData:    map[string]string{},
+			Creator: &Creator{
+				AvatarURL: "https://www.gravatar.com/avatar/e14f55d3f939977cecbf51b64ff6f861",
+				CreatedAt: NewTimestamp(time.Date(2015, 5, 22, 12, 36, 45, 309000000, time.UTC)),
+				Email:     "keith@buildkite.com",
+				ID:        "3d3c3bf0-7d58-4afe-8fe7-b3017d5504de",
+				Name:      "Keith Pitt",
+			},
+		},
+		Pipeline: &Pipeline{
+			ID:            String("849411f9-9e6d-4739-a0d8-e247088e9b52"),
+			URL:           String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline"),
+			WebURL:        String("https://buildkite.com/my-great-org/my-pipeline"),
+			Name:          String("My Pipeline"),
+			Slug:          String("my-pipeline"),
+			Repository:    String("git@github.com:my-great-org/my-pipeline"),
+			BuildsURL:     String("https://api.buildkite.com/v2/organizations/my-great-org/pipelines/my-pipeline/builds"),
+			BadgeURL:      String("https://badge.buildkite.com/58b3da999635d0ad2daae5f784e56d264343eb02526f129bfb.svg"),
+			CreatedAt:     buildTime,
+			DefaultBranch: String("main"),
+			Provider: &Provider{
+				ID:         "github",
+				WebhookURL: String("https://webhook.buildkite.com/deliver/xxx"),
+				Settings: &GitHubSettings{
+					PublishCommitStatus:        Bool(true),
+					BuildPullRequests:          Bool(true),
+					BuildPullRequestForks:      Bool(false),
+					BuildTags:                  Bool(false),
+					PublishCommitStatusPerStep: Bool(false),
+					Repository:                 String("my-great-org/my-pipeline"),
+					TriggerMode:                String("code"),
+				},
+			},
+			ScheduledBuildsCount: Int(0),
+			RunningBuildsCount:   Int(0),
+			ScheduledJobsCount:   Int(0),
+			RunningJobsCount:     Int(0),
+			WaitingJobsCount:     Int(0),
+		},
+		Sender: &WebhookSender{ID: String("8a7693f8-dbae-4783-9137-84090fce9045"), Name: String("Keith Pitt")},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("ParseWebhook returned %+v, want %+v", event, want)
+	}
+}
+
+func TestParseWebhook_Job(t *testing.T) {
+	payload := []byte(`{
+		"event": "job.started",
+		"job": {"id": "b63254c0", "state": "running"},
+		"build": {"id": "f62a1b4d"},
+		"pipeline": {"slug": "my-great-pipeline"}
+	}`)
+
+	event, err := ParseWebhook(EventJobStarted, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	want := &JobEvent{
+		Event:    String("job.started"),
+		Job:      &Job{ID: String("b63254c0"), State: String("running")},
+		Build:    &Build{ID: String("f62a1b4d")},
+		Pipeline: &Pipeline{Slug: String("my-great-pipeline")},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("ParseWebhook returned %+v, want %+v", event, want)
+	}
+}
+
+func TestParseWebhook_Agent(t *testing.T) {
+	payload := []byte(`{"event": "agent.connected", "agent": {"id": "123", "name": "my-agent"}}`)
+
+	event, err := ParseWebhook(EventAgentConnected, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	want := &AgentEvent{
+		Event: String("agent.connected"),
+		Agent: &Agent{ID: String("123"), Name: String("my-agent")},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("ParseWebhook returned %+v, want %+v", event, want)
+	}
+}
+
+func TestParseWebhook_Ping(t *testing.T) {
+	payload := []byte(`{"event": "ping", "service": {"id": "c9f8372d", "provider": "webhook"}, "organization": {"slug": "my-great-org"}}`)
+
+	event, err := ParseWebhook(EventPing, payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned error: %v", err)
+	}
+
+	want := &PingEvent{
+		Event:        String("ping"),
+		Service:      &PingService{ID: String("c9f8372d"), Provider: String("webhook")},
+		Organization: &Organization{Slug: String("my-great-org")},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("ParseWebhook returned %+v, want %+v", event, want)
+	}
+}
+
+func TestParseWebhook_unknownEvent(t *testing.T) {
+	if _, err := ParseWebhook("artifact.uploaded", []byte(`{}`)); err == nil {
+		t.Error("Expected error for unknown event type")
+	}
+}
+
+func TestParseWebhook_invalidPayload(t *testing.T) {
+	if _, err := ParseWebhook(EventBuildRunning, []byte(`{`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+// The signature vector signs the documented build.finished payload. It was
+// computed outside of Go, with
+//
+//	printf '1642080837.%s' "$body" | openssl dgst -sha256 -hmac my-webhook-secret
+const (
+	testWebhookSecret = "my-webhook-secret"
+	testWebhookBody   = testBuildWebhookPayload
+	testWebhookHeader = "timestamp=1642080837,signature=b08623b9137c7b0d6106c0864c14df5f1c3ea8a4bbeb596593f57c42ad9c293f"
+)
+
+func TestValidateWebhookSignature(t *testing.T) {
+	if err := ValidateWebhookSignature(testWebhookHeader, []byte(testWebhookBody), testWebhookSecret); err != nil {
+		t.Errorf("ValidateWebhookSignature returned error: %v", err)
+	}
+}
+
+func TestValidateWebhookSignature_mismatch(t *testing.T) {
+	tests := []struct {
+		header, body, secret string
+	}{
+		{testWebhookHeader, testWebhookBody, "wrong-secret"},
+		{testWebhookHeader, `{"event":"ping","tampered":true}`, testWebhookSecret},
+		{"timestamp=1642080838,signature=b08623b9137c7b0d6106c0864c14df5f1c3ea8a4bbeb596593f57c42ad9c293f", testWebhookBody, testWebhookSecret},
+		{"timestamp=1642080837,signature=not-hex", testWebhookBody, testWebhookSecret},
+	}
+
+	for _, tt := range tests {
+		err := ValidateWebhookSignature(tt.header, []byte(tt.body), tt.secret)
+		if err != ErrInvalidWebhookSignature {
+			t.Errorf("ValidateWebhookSignature(%q, %q, %q) returned %v, want %v", tt.header, tt.body, tt.secret, err, ErrInvalidWebhookSignature)
+		}
+	}
+}
+
+func TestValidateWebhookSignature_malformedHeader(t *testing.T) {
+	for _, header := range []string{"", "timestamp=1642080837", "signature=cff219c9", "garbage"} {
+		err := ValidateWebhookSignature(header, []byte(testWebhookBody), testWebhookSecret)
+		if err == nil || err == ErrInvalidWebhookSignature {
+			t.Errorf("ValidateWebhookSignature(%q) returned %v, want a malformed header error", header, err)
+		}
+	}
+}